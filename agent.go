@@ -1,12 +1,19 @@
 package forza
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/vitoraguila/forza/memory"
+)
 
 // Agent represents an AI agent with a role, backstory, and goal.
 type Agent struct {
 	Role      string
 	Backstory string
 	Goal      string
+
+	memory      memory.Memory
+	memoryLimit int
 }
 
 // NewAgent creates a new empty Agent.
@@ -59,15 +66,18 @@ func (a *Agent) NewLLMTask(c *LLMConfig) (LLMAgent, error) {
 		return nil, err
 	}
 
-	ok, msg := checkModel(c.provider, c.model)
-	if !ok {
-		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, msg)
-	}
-
 	factory, exists := providerFactory[c.provider]
 	if !exists {
 		return nil, fmt.Errorf("%w: %q", ErrProviderNotFound, c.provider)
 	}
 
+	if c.discoveryTTL > 0 {
+		if err := checkDiscoveredModel(c); err != nil {
+			return nil, err
+		}
+	} else if ok, msg := checkModel(c.provider, c.model); !ok {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, msg)
+	}
+
 	return factory(c, a), nil
 }
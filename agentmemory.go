@@ -0,0 +1,118 @@
+package forza
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/vitoraguila/forza/memory"
+)
+
+const defaultMemoryRecall = 5
+
+const extractorPrompt = `Extract the lasting facts worth remembering about the user from the conversation given as context: ` +
+	`preferences, personal details, plans and decisions. Skip small talk, questions and anything only relevant to the moment. ` +
+	`Write each fact as a short, self-contained sentence about the user. ` +
+	`Answer only with a JSON array of strings, or [] when there is nothing to remember.`
+
+// WithMemory gives the agent long-term memory. Before each completion, the
+// facts in m most relevant to the prompt are recalled for the subject named
+// in the context (see memory.ContextWithSubject) and added to the system
+// prompts. Tasks created before the call are not affected.
+func (a *Agent) WithMemory(m memory.Memory) *Agent {
+	a.memory = m
+	return a
+}
+
+// WithMemoryRecall sets how many facts are recalled for each completion.
+// Default is 5.
+func (a *Agent) WithMemoryRecall(n int) *Agent {
+	a.memoryLimit = n
+	return a
+}
+
+// Remember stores a fact about the subject named in ctx.
+func (a *Agent) Remember(ctx context.Context, text string, metadata map[string]string) (memory.Fact, error) {
+	if a.memory == nil {
+		return memory.Fact{}, fmt.Errorf("%w: agent has no memory", ErrInvalidConfig)
+	}
+	return a.memory.Remember(ctx, memory.Fact{Subject: memory.SubjectFromContext(ctx), Text: text, Metadata: metadata})
+}
+
+// Forget removes the fact with the given ID.
+func (a *Agent) Forget(ctx context.Context, id string) error {
+	if a.memory == nil {
+		return fmt.Errorf("%w: agent has no memory", ErrInvalidConfig)
+	}
+	return a.memory.Forget(ctx, id)
+}
+
+// ExtractMemories asks extractor for the facts worth remembering in text,
+// such as a conversation transcript, and remembers them for the subject
+// named in ctx. The extractor's user prompt is replaced, so it should be
+// dedicated to extraction.
+func (a *Agent) ExtractMemories(ctx context.Context, extractor LLMAgent, text string) ([]memory.Fact, error) {
+	if a.memory == nil {
+		return nil, fmt.Errorf("%w: agent has no memory", ErrInvalidConfig)
+	}
+	extractor.WithUserPrompt(extractorPrompt)
+	answer, err := extractor.Completion(internalTaskContext(ctx, "memory extraction"), text)
+	if err != nil {
+		return nil, err
+	}
+	var texts []string
+	if err := json.Unmarshal([]byte(stripCodeFence(answer)), &texts); err != nil {
+		return nil, fmt.Errorf("%w: extracted facts: %v", ErrInvalidOutput, err)
+	}
+
+	var facts []memory.Fact
+	for _, t := range texts {
+		if strings.TrimSpace(t) == "" {
+			continue
+		}
+		fact, err := a.Remember(ctx, t, map[string]string{"source": "extracted"})
+		if err != nil {
+			return facts, err
+		}
+		facts = append(facts, fact)
+	}
+	return facts, nil
+}
+
+// agentMemory is the long-term memory a provider recalls from.
+type agentMemory struct {
+	store memory.Memory
+	limit int
+}
+
+func (a *Agent) agentMemory() agentMemory {
+	limit := a.memoryLimit
+	if limit <= 0 {
+		limit = defaultMemoryRecall
+	}
+	return agentMemory{store: a.memory, limit: limit}
+}
+
+// systemPrompts returns prompts followed by the facts recalled for the user
+// prompt and its context, if any.
+func (m agentMemory) systemPrompts(ctx context.Context, prompts []agentPrompts, userPrompt *string, params []string) ([]agentPrompts, error) {
+	if m.store == nil || userPrompt == nil {
+		return prompts, nil
+	}
+	query := strings.Join(append([]string{*userPrompt}, params...), "\n")
+	facts, err := m.store.Recall(ctx, memory.Query{Subject: memory.SubjectFromContext(ctx), Text: query, Limit: m.limit})
+	if err != nil {
+		return nil, fmt.Errorf("recalling memories: %w", err)
+	}
+	if len(facts) == 0 {
+		return prompts, nil
+	}
+
+	var b strings.Builder
+	b.WriteString("What you remember from earlier conversations:")
+	for _, f := range facts {
+		fmt.Fprintf(&b, "\n- %s (%s)", f.Text, f.Updated.Format("2006-01-02"))
+	}
+	return append(prompts[:len(prompts):len(prompts)], agentPrompts{Role: agentRoleSystem, Context: b.String()}), nil
+}
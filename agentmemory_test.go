@@ -0,0 +1,102 @@
+package forza
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/vitoraguila/forza/memory"
+)
+
+func TestAgentMemory_RecalledIntoSystemPrompts(t *testing.T) {
+	var system []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openai.ChatCompletionRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		system = nil
+		for _, m := range req.Messages {
+			if m.Role == openai.ChatMessageRoleSystem {
+				system = append(system, m.Content)
+			}
+		}
+		json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "ok"}}},
+		})
+	}))
+	defer server.Close()
+
+	store, err := memory.NewFileStore(filepath.Join(t.TempDir(), "memory.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	agent := NewAgent().WithRole("Barista").WithBackstory("Knows the regulars").WithGoal("serve the right drink").WithMemory(store)
+	ana := memory.ContextWithSubject(context.Background(), "ana")
+	if _, err := agent.Remember(ana, "Orders a flat white with oat milk", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	agent.Remember(memory.ContextWithSubject(context.Background(), "bo"), "Orders a black filter coffee", nil)
+
+	task, err := agent.NewLLMTask(NewLLMConfig().WithProvider(ProviderOllama).WithModel(OllamaModels.Llama31).WithOllamaCredentials(server.URL + "/v1"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	task.WithUserPrompt("What milk does she take?")
+	if _, err := task.Completion(ana); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(system) != 3 || !strings.Contains(system[2], "- Orders a flat white with oat milk (") || strings.Contains(system[2], "black") {
+		t.Errorf("expected ana's fact in an extra system prompt, got %q", system)
+	}
+
+	if _, err := task.Completion(memory.ContextWithSubject(context.Background(), "cy")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(system) != 2 {
+		t.Errorf("expected no memory prompt without matching facts, got %q", system)
+	}
+}
+
+func TestAgentMemory_ExtractAndForget(t *testing.T) {
+	store, _ := memory.NewFileStore(filepath.Join(t.TempDir(), "memory.json"))
+	agent := NewAgent().WithMemory(store)
+	ctx := memory.ContextWithSubject(context.Background(), "ana")
+
+	extractor := &stubAgent{}
+	extractor.complete = func(ctx context.Context, params ...string) (string, error) {
+		if !strings.Contains(params[0], "moving to Porto") {
+			return "", errors.New("expected the conversation as context")
+		}
+		return "```json\n[\"Is moving to Porto in May\", \"\", \"Is allergic to nuts\"]\n```", nil
+	}
+	facts, err := agent.ExtractMemories(ctx, extractor, "user: I'm moving to Porto in May, and remember I'm allergic to nuts")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(facts) != 2 || facts[0].Subject != "ana" || facts[1].Metadata["source"] != "extracted" {
+		t.Fatalf("unexpected facts %+v", facts)
+	}
+	if !strings.Contains(extractor.prompt, "JSON array") {
+		t.Errorf("expected extraction instructions, got %q", extractor.prompt)
+	}
+
+	if err := agent.Forget(ctx, facts[0].ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if remaining := store.Facts("ana"); len(remaining) != 1 || remaining[0].Text != "Is allergic to nuts" {
+		t.Errorf("unexpected remaining facts %+v", remaining)
+	}
+
+	extractor.complete = func(context.Context, ...string) (string, error) { return "nothing", nil }
+	if _, err := agent.ExtractMemories(ctx, extractor, "hi"); !errors.Is(err, ErrInvalidOutput) {
+		t.Errorf("expected ErrInvalidOutput, got %v", err)
+	}
+	if _, err := NewAgent().Remember(ctx, "x", nil); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig without memory, got %v", err)
+	}
+}
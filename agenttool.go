@@ -0,0 +1,118 @@
+package forza
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vitoraguila/forza/tools"
+)
+
+const defaultAgentToolMaxDepth = 3
+
+// AgentTool exposes an agent as a tools.Tool, so one agent can consult a
+// specialist agent through function calling, on the same or a different
+// provider and model. Each call runs a fresh LLM task with the tool's input.
+//
+// Calls are guarded against recursion: an AgentTool reached again through
+// its own calls, or nested deeper than its maximum depth, answers with a
+// refusal instead of running. When the context carries a CostTracker, each
+// call is tracked under a child named after the tool.
+type AgentTool struct {
+	name        string
+	description string
+	agent       *Agent
+	config      *LLMConfig
+	tools       []tools.Tool
+	prompt      func(input string) (prompt string, context []string)
+	maxDepth    int
+}
+
+var _ tools.Tool = (*AgentTool)(nil)
+
+// NewAgentTool wraps agent and config as a tool with the given name and
+// description. The agent is validated like Agent.NewLLMTask. By default the
+// caller's input is the sub-agent's whole user prompt.
+func NewAgentTool(name, description string, agent *Agent, config *LLMConfig) (*AgentTool, error) {
+	if name == "" {
+		return nil, fmt.Errorf("%w: agent tool name is required", ErrInvalidConfig)
+	}
+	if agent == nil || config == nil {
+		return nil, fmt.Errorf("%w: agent tool %q needs an Agent and a Config", ErrInvalidConfig, name)
+	}
+	if _, err := agent.NewLLMTask(config); err != nil {
+		return nil, fmt.Errorf("agent tool %q: %w", name, err)
+	}
+	return &AgentTool{
+		name:        name,
+		description: description,
+		agent:       agent,
+		config:      config,
+		prompt:      func(input string) (string, []string) { return input, nil },
+		maxDepth:    defaultAgentToolMaxDepth,
+	}, nil
+}
+
+// WithTools gives the sub-agent its own tools.
+func (t *AgentTool) WithTools(tt ...tools.Tool) *AgentTool {
+	t.tools = append(t.tools, tt...)
+	return t
+}
+
+// WithPrompt gives the sub-agent a fixed user prompt and passes the caller's
+// input as its context.
+func (t *AgentTool) WithPrompt(prompt string) *AgentTool {
+	t.prompt = func(input string) (string, []string) { return prompt, []string{input} }
+	return t
+}
+
+// WithPromptFunc builds the sub-agent's user prompt from the caller's input.
+func (t *AgentTool) WithPromptFunc(fn func(input string) string) *AgentTool {
+	t.prompt = func(input string) (string, []string) { return fn(input), nil }
+	return t
+}
+
+// WithMaxDepth limits how many agent tools may be nested in one call chain,
+// this one included. Default is 3.
+func (t *AgentTool) WithMaxDepth(n int) *AgentTool {
+	t.maxDepth = n
+	return t
+}
+
+func (t *AgentTool) Name() string {
+	return t.name
+}
+
+func (t *AgentTool) Description() string {
+	if t.description != "" {
+		return t.description
+	}
+	return fmt.Sprintf("Consult a %s whose goal is %s. The input is your request to them; the result is their answer.", t.agent.Role, t.agent.Goal)
+}
+
+type agentToolStackKey struct{}
+
+// Call runs the sub-agent on input.
+func (t *AgentTool) Call(ctx context.Context, input string) (string, error) {
+	stack, _ := ctx.Value(agentToolStackKey{}).([]string)
+	for _, name := range stack {
+		if name == t.name {
+			return fmt.Sprintf("Cannot consult %s: it is already working on this request (%s). Answer without it.", t.name, strings.Join(append(stack, t.name), " -> ")), nil
+		}
+	}
+	if len(stack) >= t.maxDepth {
+		return fmt.Sprintf("Cannot consult %s: the limit of %d nested agents is reached. Answer without it.", t.name, t.maxDepth), nil
+	}
+	ctx = context.WithValue(ctx, agentToolStackKey{}, append(stack[:len(stack):len(stack)], t.name))
+
+	task, err := t.agent.NewLLMTask(t.config)
+	if err != nil {
+		return "", err
+	}
+	if len(t.tools) > 0 {
+		task.WithTools(t.tools...)
+	}
+	prompt, params := t.prompt(input)
+	task.WithUserPrompt(prompt)
+	return task.Completion(namedTaskContext(ctx, t.name), params...)
+}
@@ -0,0 +1,70 @@
+package forza
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAgentTool_ConsultsSpecialist(t *testing.T) {
+	server, requests := teamServer(t)
+	config := teamConfig(server.URL)
+
+	expert, err := NewAgentTool("ask_lawyer", "", teamAgent("lawyer"), config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expert.WithPrompt("Review this clause")
+
+	task, _ := teamAgent("assistant").NewLLMTask(config)
+	task.WithTools(expert)
+	task.WithUserPrompt("draft a contract")
+	answer, err := task.Completion(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := "synthesized: done: Review this clause" + contextSeparator + "subtask for ask_lawyer"; answer != want {
+		t.Errorf("expected the specialist's answer, got %q", answer)
+	}
+	if desc := (*requests)[0].Tools[0].Function.Description; !strings.Contains(desc, "lawyer whose goal is lawyer work") {
+		t.Errorf("expected a description from the agent, got %q", desc)
+	}
+}
+
+func TestAgentTool_RecursionGuard(t *testing.T) {
+	server, _ := teamServer(t)
+	config := teamConfig(server.URL)
+
+	// The tool's sub-agent is given the tool itself, so the model calls it again.
+	self, _ := NewAgentTool("ask_self", "ask yourself", teamAgent("thinker"), config)
+	self.WithTools(self)
+	answer, err := self.Call(context.Background(), "think")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(answer, "Cannot consult ask_self: it is already working on this request (ask_self -> ask_self)") {
+		t.Errorf("expected the recursive call to be refused, got %q", answer)
+	}
+
+	// Distinct tools nested too deep are refused as well.
+	inner, _ := NewAgentTool("inner", "inner", teamAgent("inner"), config)
+	outer, _ := NewAgentTool("outer", "outer", teamAgent("outer"), config)
+	outer.WithTools(inner).WithMaxDepth(1)
+	inner.WithMaxDepth(1)
+	answer, err = outer.Call(context.Background(), "go")
+	if err != nil || !strings.Contains(answer, "limit of 1 nested agents") {
+		t.Errorf("expected the depth limit, got %q, %v", answer, err)
+	}
+}
+
+func TestNewAgentTool_Validates(t *testing.T) {
+	config := teamConfig("http://localhost")
+	if _, err := NewAgentTool("x", "", NewAgent(), config); !errors.Is(err, ErrMissingRole) {
+		t.Errorf("expected ErrMissingRole, got %v", err)
+	}
+	if _, err := NewAgentTool("", "", teamAgent("a"), config); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig, got %v", err)
+	}
+}
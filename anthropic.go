@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
+	"slices"
 
 	"github.com/vitoraguila/forza/tools"
 )
@@ -17,9 +19,10 @@ const anthropicAPIVersion = "2023-06-01"
 type anthropicProvider struct {
 	config        *LLMConfig
 	functions     []anthropicToolDef
-	fnExecutable  map[string]func(param string) (string, error)
+	fnExecutable  map[string]toolFunc
 	builtinTools  map[string]bool
 	systemPrompts []agentPrompts
+	memory        agentMemory
 	userPrompt    *string
 	httpClient    *http.Client
 }
@@ -66,9 +69,15 @@ type anthropicResponse struct {
 	Role    string                  `json:"role"`
 	Content []anthropicContentBlock `json:"content"`
 	Model   string                  `json:"model"`
+	Usage   *anthropicUsage         `json:"usage,omitempty"`
 	Error   *anthropicError         `json:"error,omitempty"`
 }
 
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
 type anthropicError struct {
 	Type    string `json:"type"`
 	Message string `json:"message"`
@@ -77,13 +86,14 @@ type anthropicError struct {
 // --- Constructor ---
 
 func newAnthropic(c *LLMConfig, a *Agent) LLMAgent {
-	fnExecutable := make(map[string]func(param string) (string, error))
+	fnExecutable := make(map[string]toolFunc)
 	builtinTools := make(map[string]bool)
 
 	return &anthropicProvider{
 		config:        c,
 		fnExecutable:  fnExecutable,
 		systemPrompts: buildSystemPrompts(a),
+		memory:        a.agentMemory(),
 		builtinTools:  builtinTools,
 		httpClient:    &http.Client{Timeout: c.timeout},
 	}
@@ -106,11 +116,7 @@ func (a *anthropicProvider) WithTools(t ...tools.Tool) {
 				"required": []string{"input"},
 			},
 		})
-		a.fnExecutable[tool.Name()] = func(t tools.Tool) func(string) (string, error) {
-			return func(input string) (string, error) {
-				return t.Call(context.Background(), input)
-			}
-		}(tool)
+		a.fnExecutable[tool.Name()] = tool.Call
 		a.builtinTools[tool.Name()] = true
 	}
 }
@@ -138,11 +144,35 @@ func (a *anthropicProvider) AddCustomTools(name string, description string, para
 			"required":   required,
 		},
 	})
-	a.fnExecutable[name] = fn
+	a.fnExecutable[name] = customToolFunc(fn)
+}
+
+// withRunTools returns a copy of the provider for a single completion, with
+// extra tools added by the run.
+func (a *anthropicProvider) withRunTools(extra []tools.Tool) *anthropicProvider {
+	call := &anthropicProvider{
+		config:        a.config,
+		functions:     slices.Clone(a.functions),
+		fnExecutable:  maps.Clone(a.fnExecutable),
+		builtinTools:  maps.Clone(a.builtinTools),
+		systemPrompts: a.systemPrompts,
+		memory:        a.memory,
+		userPrompt:    a.userPrompt,
+		httpClient:    a.httpClient,
+	}
+	call.WithTools(extra...)
+	return call
 }
 
 func (a *anthropicProvider) Completion(ctx context.Context, params ...string) (string, error) {
-	userPrompt, err := resolveUserPrompt(a.userPrompt, params)
+	if extra := runTools(ctx, a.fnExecutable); len(extra) > 0 {
+		return a.withRunTools(extra).Completion(ctx, params...)
+	}
+	systemPrompts, err := a.memory.systemPrompts(ctx, a.systemPrompts, a.userPrompt, params)
+	if err != nil {
+		return "", err
+	}
+	userPrompt, err := fitUserPrompt(a.config, systemPrompts, a.userPrompt, params)
 	if err != nil {
 		return "", err
 	}
@@ -154,7 +184,7 @@ func (a *anthropicProvider) Completion(ctx context.Context, params ...string) (s
 
 	// Build system prompt
 	var systemPrompt string
-	for _, p := range a.systemPrompts {
+	for _, p := range systemPrompts {
 		if systemPrompt != "" {
 			systemPrompt += "\n"
 		}
@@ -167,15 +197,37 @@ func (a *anthropicProvider) Completion(ctx context.Context, params ...string) (s
 	}
 
 	// Build request
+	info := a.config.modelInfo()
 	req := anthropicRequest{
-		Model:       a.config.model,
-		MaxTokens:   a.config.maxTokens,
-		Temperature: a.config.temperature,
-		System:      systemPrompt,
-		Messages:    messages,
+		Model:     a.config.model,
+		MaxTokens: a.config.outputTokens(),
+		System:    systemPrompt,
+		Messages:  messages,
 	}
-	if len(a.functions) > 0 {
+	if !info.Reasoning {
+		req.Temperature = a.config.temperature
+	}
+	if len(a.functions) > 0 && info.SupportsTools {
 		req.Tools = a.functions
+	} else if len(a.functions) > 0 {
+		reactTools := make([]reactTool, len(a.functions))
+		for i, f := range a.functions {
+			reactTools[i] = reactTool{name: f.Name, description: f.Description, parameters: f.InputSchema, builtin: a.builtinTools[f.Name], fn: a.fnExecutable[f.Name]}
+		}
+		return runReAct(ctx, userPrompt, reactTools, func(ctx context.Context, prompt string) (string, error) {
+			req.Messages = []anthropicMessage{{Role: "user", Content: prompt}}
+			resp, err := a.doRequest(ctx, apiKey, req)
+			if err != nil {
+				return "", err
+			}
+			var text string
+			for _, block := range resp.Content {
+				if block.Type == "text" {
+					text += block.Text
+				}
+			}
+			return text, nil
+		})
 	}
 
 	resp, err := a.doRequest(ctx, apiKey, req)
@@ -219,7 +271,7 @@ func (a *anthropicProvider) Completion(ctx context.Context, params ...string) (s
 				toolInput = extractBuiltinToolInput(toolInput)
 			}
 
-			content, err := fn(toolInput)
+			content, err := fn(ctx, toolInput)
 			if err != nil {
 				return "", fmt.Errorf("%w: tool %q: %v", ErrToolCallFailed, toolBlock.Name, err)
 			}
@@ -239,7 +291,7 @@ func (a *anthropicProvider) Completion(ctx context.Context, params ...string) (s
 
 		resp, err = a.doRequest(ctx, apiKey, req)
 		if err != nil {
-			return "", fmt.Errorf("%w: follow-up after tool call: %v", ErrCompletionFailed, err)
+			return "", fmt.Errorf("%w: follow-up after tool call: %w", ErrCompletionFailed, err)
 		}
 	}
 
@@ -261,11 +313,23 @@ func (a *anthropicProvider) doRequest(ctx context.Context, apiKey string, reqBod
 	req.Header.Set("x-api-key", apiKey)
 	req.Header.Set("anthropic-version", anthropicAPIVersion)
 
+	if err := checkBudget(ctx); err != nil {
+		return nil, err
+	}
+
 	var anthropicResp anthropicResponse
+	var usage Usage
 	doFn := func() error {
+		res, err := reserveRate(ctx, a.config, estimateTokens(string(body))+reqBody.MaxTokens)
+		if err != nil {
+			return err
+		}
+		usage = Usage{}
+		defer func() { res.reconcile(usage.TotalTokens()) }()
+
 		resp, err := a.httpClient.Do(req)
 		if err != nil {
-			return fmt.Errorf("%w: request failed: %v", ErrCompletionFailed, err)
+			return fmt.Errorf("%w: request failed: %w", ErrCompletionFailed, err)
 		}
 		defer resp.Body.Close()
 
@@ -295,6 +359,13 @@ func (a *anthropicProvider) doRequest(ctx context.Context, apiKey string, reqBod
 			return fmt.Errorf("%w: failed to parse response: %v", ErrCompletionFailed, err)
 		}
 
+		if anthropicResp.Usage != nil {
+			usage = Usage{
+				PromptTokens:     anthropicResp.Usage.InputTokens,
+				CompletionTokens: anthropicResp.Usage.OutputTokens,
+			}
+		}
+
 		if anthropicResp.Error != nil {
 			return fmt.Errorf("%w: API error [%s]: %s", ErrCompletionFailed, anthropicResp.Error.Type, anthropicResp.Error.Message)
 		}
@@ -305,6 +376,7 @@ func (a *anthropicProvider) doRequest(ctx context.Context, apiKey string, reqBod
 	if err := withRetry(ctx, a.config.maxRetries, doFn); err != nil {
 		return nil, err
 	}
+	recordUsage(ctx, a.config.model, usage)
 
 	return &anthropicResp, nil
 }
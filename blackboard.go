@@ -0,0 +1,235 @@
+package forza
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vitoraguila/forza/tools"
+)
+
+const (
+	memoryGetToolName = "memory_get"
+	memorySetToolName = "memory_set"
+)
+
+// BlackboardChange is one write to a Blackboard. Author is the name of the
+// task that made it, such as "task 2" or a template step's name, when known.
+type BlackboardChange struct {
+	Key      string    `json:"key"`
+	Value    string    `json:"value"`
+	Previous string    `json:"previous,omitempty"`
+	Author   string    `json:"author,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// Blackboard is a key-value store shared by the agents of a pipeline run,
+// so that, for example, concurrent researchers can post findings that a
+// later writer reads. It is safe for concurrent use and keeps a log of every
+// change.
+//
+// Attach it to a pipeline with Pipeline.WithBlackboard, or to any context
+// with ContextWithBlackboard. Agents reach it through MemoryTools, added to
+// all of them by the WithMemoryTools option, and template chains read its
+// values as template variables.
+type Blackboard struct {
+	mu      sync.RWMutex
+	values  map[string]string
+	changes []BlackboardChange
+}
+
+// NewBlackboard creates an empty Blackboard.
+func NewBlackboard() *Blackboard {
+	return &Blackboard{values: make(map[string]string)}
+}
+
+// Get returns the value of key and whether it is set.
+func (b *Blackboard) Get(key string) (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	v, ok := b.values[key]
+	return v, ok
+}
+
+// Set stores value under key and records the change under author, which may
+// be empty.
+func (b *Blackboard) Set(key, value, author string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.changes = append(b.changes, BlackboardChange{Key: key, Value: value, Previous: b.values[key], Author: author, Time: time.Now()})
+	b.values[key] = value
+}
+
+// Keys returns the keys that are set, sorted.
+func (b *Blackboard) Keys() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	keys := make([]string, 0, len(b.values))
+	for k := range b.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Snapshot returns a copy of every key and value.
+func (b *Blackboard) Snapshot() map[string]string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	values := make(map[string]string, len(b.values))
+	for k, v := range b.values {
+		values[k] = v
+	}
+	return values
+}
+
+// Changes returns the change log, oldest first.
+func (b *Blackboard) Changes() []BlackboardChange {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return append([]BlackboardChange(nil), b.changes...)
+}
+
+// BlackboardOption configures how a Blackboard is shared with a run.
+type BlackboardOption func(*blackboardRun)
+
+// WithMemoryTools gives every agent that runs with the blackboard the
+// memory_get and memory_set tools, in addition to its own, without
+// registering them on each agent. Internal agents that must answer in a fixed
+// format, such as router classifiers, critics, planners, summarizers and
+// memory extractors, don't get them. Agents on models without native function
+// calling then use the ReAct text loop.
+func WithMemoryTools() BlackboardOption {
+	return func(r *blackboardRun) {
+		r.memoryTools = true
+	}
+}
+
+// blackboardRun is a Blackboard as attached to a run.
+type blackboardRun struct {
+	board       *Blackboard
+	memoryTools bool
+}
+
+func newBlackboardRun(b *Blackboard, opts []BlackboardOption) *blackboardRun {
+	run := &blackboardRun{board: b}
+	for _, opt := range opts {
+		opt(run)
+	}
+	return run
+}
+
+type blackboardKey struct{}
+
+// ContextWithBlackboard returns a copy of ctx that carries b.
+func ContextWithBlackboard(ctx context.Context, b *Blackboard, opts ...BlackboardOption) context.Context {
+	return context.WithValue(ctx, blackboardKey{}, newBlackboardRun(b, opts))
+}
+
+// BlackboardFromContext returns the Blackboard carried by ctx, or nil.
+func BlackboardFromContext(ctx context.Context) *Blackboard {
+	if run, _ := ctx.Value(blackboardKey{}).(*blackboardRun); run != nil {
+		return run.board
+	}
+	return nil
+}
+
+// internalTaskContext names an internal call, such as a router classifier or
+// a critic, whose agent must answer in a fixed format. The run's memory tools
+// are not added to it, though the blackboard stays reachable.
+func internalTaskContext(ctx context.Context, name string) context.Context {
+	ctx = namedTaskContext(ctx, name)
+	if run, _ := ctx.Value(blackboardKey{}).(*blackboardRun); run != nil && run.memoryTools {
+		ctx = context.WithValue(ctx, blackboardKey{}, &blackboardRun{board: run.board})
+	}
+	return ctx
+}
+
+// runTools returns the tools the run in ctx adds to every agent, leaving out
+// those already in registered.
+func runTools(ctx context.Context, registered map[string]toolFunc) []tools.Tool {
+	run, _ := ctx.Value(blackboardKey{}).(*blackboardRun)
+	if run == nil || !run.memoryTools {
+		return nil
+	}
+	var extra []tools.Tool
+	for _, t := range MemoryTools() {
+		if _, ok := registered[t.Name()]; !ok {
+			extra = append(extra, t)
+		}
+	}
+	return extra
+}
+
+// MemoryTools returns the memory_get and memory_set tools, which read and
+// write the Blackboard of the run they are called in. WithMemoryTools adds
+// them to every agent of a run; to give them only to some agents, register
+// them on those instead:
+//
+//	task.WithTools(forza.MemoryTools()...)
+//
+// The tools find the blackboard through the Completion context, so one agent
+// can serve runs with different blackboards.
+func MemoryTools() []tools.Tool {
+	return []tools.Tool{memoryGetTool{}, memorySetTool{}}
+}
+
+type memoryGetTool struct{}
+
+func (memoryGetTool) Name() string { return memoryGetToolName }
+
+func (memoryGetTool) Description() string {
+	return "Read from the memory shared with the other agents. The input is a key; leave it empty to list every key and value."
+}
+
+func (memoryGetTool) Call(ctx context.Context, input string) (string, error) {
+	b := BlackboardFromContext(ctx)
+	if b == nil {
+		return "No shared memory is available.", nil
+	}
+	key := strings.TrimSpace(input)
+	if key == "" {
+		values := b.Snapshot()
+		if len(values) == 0 {
+			return "The shared memory is empty.", nil
+		}
+		lines := make([]string, 0, len(values))
+		for k, v := range values {
+			lines = append(lines, k+": "+v)
+		}
+		sort.Strings(lines)
+		return strings.Join(lines, "\n"), nil
+	}
+	if v, ok := b.Get(key); ok {
+		return v, nil
+	}
+	return fmt.Sprintf("Nothing is stored under %q. Stored keys: %s.", key, strings.Join(b.Keys(), ", ")), nil
+}
+
+type memorySetTool struct{}
+
+func (memorySetTool) Name() string { return memorySetToolName }
+
+func (memorySetTool) Description() string {
+	return `Write to the memory shared with the other agents, replacing any earlier value. The input is a JSON object {"key": "<key>", "value": "<value>"}.`
+}
+
+func (memorySetTool) Call(ctx context.Context, input string) (string, error) {
+	b := BlackboardFromContext(ctx)
+	if b == nil {
+		return "No shared memory is available.", nil
+	}
+	var entry struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal([]byte(stripCodeFence(input)), &entry); err != nil || strings.TrimSpace(entry.Key) == "" {
+		return `Invalid input: expected a JSON object {"key": "<key>", "value": "<value>"}.`, nil
+	}
+	b.Set(strings.TrimSpace(entry.Key), entry.Value, taskName(ctx))
+	return "Stored.", nil
+}
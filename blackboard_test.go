@@ -0,0 +1,184 @@
+package forza
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// poster writes a finding through the memory_set tool, as an agent would.
+func poster(key, value string) TaskChainFn {
+	return func(ctx context.Context, _ ...string) (string, error) {
+		set := MemoryTools()[1]
+		return set.Call(ctx, fmt.Sprintf(`{"key": %q, "value": %q}`, key, value))
+	}
+}
+
+func TestBlackboard_SharedAcrossPipelineRuns(t *testing.T) {
+	board := NewBlackboard()
+	p := NewPipeline().WithBlackboard(board)
+	for i := 1; i <= 5; i++ {
+		p.AddTasks(poster(fmt.Sprintf("finding_%d", i), fmt.Sprintf("fact %d", i)))
+	}
+	if _, err := p.RunConcurrently(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if keys := board.Keys(); len(keys) != 5 {
+		t.Fatalf("expected 5 findings, got %v", keys)
+	}
+
+	writer := promptEcho("post")
+	chain, err := p.CreateTemplateChain(TemplateStep{Name: "post", Agent: writer, Prompt: "Write about {{.finding_1}} and {{.finding_5}} for {{.audience}}"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	vars, err := chain.Run(context.Background(), map[string]string{"audience": "gophers"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if vars["post"] != "post(Write about fact 1 and fact 5 for gophers)" {
+		t.Errorf("expected blackboard values as template variables, got %q", vars["post"])
+	}
+	if _, err := chain.Run(context.Background(), nil); !errors.Is(err, ErrMissingVariable) || !strings.Contains(err.Error(), "audience") {
+		t.Errorf("expected the missing input to be reported, got %v", err)
+	}
+
+	chained := p.CreateChain(poster("finding_1", "revised"), poster("summary", "done"))
+	if _, err := chained(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	changes := board.Changes()
+	last := changes[len(changes)-2]
+	if last.Key != "finding_1" || last.Value != "revised" || last.Previous != "fact 1" || last.Author != "task 1" {
+		t.Errorf("unexpected change %+v", last)
+	}
+	authors := make(map[string]bool)
+	for _, c := range changes[:5] {
+		authors[c.Author] = true
+	}
+	if len(authors) != 5 {
+		t.Errorf("expected each concurrent task to be recorded as the author, got %v", authors)
+	}
+}
+
+func TestMemoryTools(t *testing.T) {
+	get, set := MemoryTools()[0], MemoryTools()[1]
+	if get.Name() != "memory_get" || set.Name() != "memory_set" {
+		t.Fatalf("unexpected tool names %q, %q", get.Name(), set.Name())
+	}
+
+	if out, _ := get.Call(context.Background(), "x"); !strings.Contains(out, "No shared memory") {
+		t.Errorf("expected a no-memory answer outside a run, got %q", out)
+	}
+
+	board := NewBlackboard()
+	ctx := ContextWithBlackboard(namedTaskContext(context.Background(), "researcher"), board)
+	tests := []struct {
+		tool  string
+		input string
+		want  string
+	}{
+		{"get", "", "The shared memory is empty."},
+		{"set", "not json", "Invalid input"},
+		{"set", "```json\n{\"key\": \" topic \", \"value\": \"Go\"}\n```", "Stored."},
+		{"set", `{"key": "audience", "value": "gophers"}`, "Stored."},
+		{"get", "topic", "Go"},
+		{"get", "missing", `Nothing is stored under "missing". Stored keys: audience, topic.`},
+		{"get", "", "audience: gophers\ntopic: Go"},
+	}
+	for _, tt := range tests {
+		tool := get
+		if tt.tool == "set" {
+			tool = set
+		}
+		out, err := tool.Call(ctx, tt.input)
+		if err != nil || !strings.HasPrefix(out, tt.want) {
+			t.Errorf("%s(%q) = %q, %v; want %q", tt.tool, tt.input, out, err, tt.want)
+		}
+	}
+	if c := board.Changes(); len(c) != 2 || c[0].Author != "researcher" || c[0].Key != "topic" {
+		t.Errorf("unexpected change log %+v", c)
+	}
+}
+
+func TestWithMemoryTools(t *testing.T) {
+	var offered []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openai.ChatCompletionRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		offered = nil
+		for _, tool := range req.Tools {
+			offered = append(offered, tool.Function.Name)
+		}
+		msg := openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "done"}
+		if last := req.Messages[len(req.Messages)-1]; last.Role == openai.ChatMessageRoleUser && len(offered) > 0 {
+			msg = openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, ToolCalls: []openai.ToolCall{{
+				ID:       "call_1",
+				Type:     openai.ToolTypeFunction,
+				Function: openai.FunctionCall{Name: "memory_set", Arguments: `{"input": "{\"key\": \"topic\", \"value\": \"Go\"}"}`},
+			}}}
+		}
+		json.NewEncoder(w).Encode(openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{Message: msg}}})
+	}))
+	defer server.Close()
+
+	task := newTestOllamaTask(server.URL)
+	task.WithUserPrompt("pick a topic")
+	board := NewBlackboard()
+	p := NewPipeline().WithBlackboard(board, WithMemoryTools())
+	p.AddTasks(task.Completion)
+	if _, err := p.RunSequentially(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(offered, ",") != "memory_get,memory_set" {
+		t.Errorf("expected the memory tools to be offered, got %v", offered)
+	}
+	if v, ok := board.Get("topic"); !ok || v != "Go" {
+		t.Errorf("expected the agent to write to the blackboard, got %q", v)
+	}
+
+	if _, err := task.Completion(ContextWithBlackboard(context.Background(), board)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(offered) != 0 {
+		t.Errorf("expected the tools only for runs that ask for them, got %v", offered)
+	}
+}
+
+func TestWithMemoryTools_SkipsInternalAgents(t *testing.T) {
+	offered := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openai.ChatCompletionRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		answer, kind := "done", "worker"
+		for _, m := range req.Messages {
+			if strings.Contains(m.Content, "Choose the route") {
+				answer, kind = `{"route": "write", "rationale": "it asks for prose"}`, "classifier"
+			}
+		}
+		offered[kind] = len(req.Tools)
+		json.NewEncoder(w).Encode(openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{
+			Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: answer},
+		}}})
+	}))
+	defer server.Close()
+
+	writer := newTestOllamaTask(server.URL)
+	writer.WithUserPrompt("write it")
+	p := NewPipeline().WithBlackboard(NewBlackboard(), WithMemoryTools())
+	router := p.CreateRouter(Route{Name: "write", Task: writer.Completion}).WithLLM(newTestOllamaTask(server.URL))
+	p.AddTasks(router.Task())
+	if _, err := p.RunSequentially(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if offered["classifier"] != 0 || offered["worker"] != 2 {
+		t.Errorf("expected the memory tools for the route's worker only, got %v", offered)
+	}
+}
@@ -0,0 +1,88 @@
+package forza
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker_OpensAfterThreshold(t *testing.T) {
+	b := newCircuitBreaker(2, time.Minute)
+
+	b.failure()
+	if !b.allow() {
+		t.Fatal("expected breaker to stay closed below the threshold")
+	}
+	b.failure()
+	if b.allow() {
+		t.Error("expected breaker to open at the threshold")
+	}
+}
+
+func TestCircuitBreaker_SuccessResetsFailures(t *testing.T) {
+	b := newCircuitBreaker(2, time.Minute)
+
+	b.failure()
+	b.success()
+	b.failure()
+	if !b.allow() {
+		t.Error("expected success to reset the consecutive failure count")
+	}
+}
+
+func TestCircuitBreaker_HalfOpenTrial(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	b := newCircuitBreaker(1, 10*time.Second)
+	b.now = clock.Now
+
+	b.failure()
+	if b.allow() {
+		t.Fatal("expected breaker to be open")
+	}
+
+	clock.Advance(10 * time.Second)
+	if !b.allow() {
+		t.Fatal("expected a trial request after the cooldown")
+	}
+	if b.allow() {
+		t.Error("expected only one trial request while half-open")
+	}
+
+	b.failure()
+	if b.allow() {
+		t.Error("expected a failed trial to reopen the breaker")
+	}
+
+	clock.Advance(10 * time.Second)
+	if !b.allow() {
+		t.Fatal("expected another trial after the second cooldown")
+	}
+	b.success()
+	if !b.allow() {
+		t.Error("expected a successful trial to close the breaker")
+	}
+}
+
+func TestCircuitBreaker_Release(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	b := newCircuitBreaker(1, time.Second)
+	b.now = clock.Now
+
+	b.failure()
+	clock.Advance(time.Second)
+	b.allow()
+	b.release()
+
+	if !b.allow() {
+		t.Error("expected a released trial to allow another trial")
+	}
+}
+
+func TestNewCircuitBreaker_Defaults(t *testing.T) {
+	b := newCircuitBreaker(0, 0)
+	if b.threshold != defaultBreakerThreshold {
+		t.Errorf("expected default threshold %d, got %d", defaultBreakerThreshold, b.threshold)
+	}
+	if b.cooldown != defaultBreakerCooldown {
+		t.Errorf("expected default cooldown %v, got %v", defaultBreakerCooldown, b.cooldown)
+	}
+}
@@ -0,0 +1,171 @@
+package forza
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ModelInfo describes the limits and capabilities of a model. Adapters
+// consult it to shape requests: tools are dropped for models without tool
+// support, reasoning models get max_completion_tokens instead of max_tokens
+// and no temperature, and max tokens are clamped to MaxOutputTokens.
+//
+// Capability flags are taken literally, so set every flag that applies when
+// registering a model.
+type ModelInfo struct {
+	Provider             string `json:"provider"`
+	Name                 string `json:"name"`
+	ContextWindow        int    `json:"contextWindow"`   // tokens; 0 if unknown
+	MaxOutputTokens      int    `json:"maxOutputTokens"` // tokens; 0 if unknown
+	SupportsTools        bool   `json:"supportsTools"`
+	SupportsVision       bool   `json:"supportsVision"`
+	SupportsSystemPrompt bool   `json:"supportsSystemPrompt"`
+	Reasoning            bool   `json:"reasoning"` // rejects temperature; uses max_completion_tokens
+}
+
+// catalog maps provider -> model name -> ModelInfo. Guarded by catalogMu.
+// Azure OpenAI shares the OpenAI entries.
+var (
+	catalogMu sync.RWMutex
+	catalog   = make(map[string]map[string]ModelInfo)
+)
+
+func init() {
+	for _, m := range builtinModels {
+		if err := RegisterModel(m); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// builtinModels seeds the catalog with the predefined model constants.
+var builtinModels = []ModelInfo{
+	{Provider: ProviderOpenAi, Name: OpenAIModels.GPT35Turbo, ContextWindow: 16385, MaxOutputTokens: 4096, SupportsTools: true, SupportsSystemPrompt: true},
+	{Provider: ProviderOpenAi, Name: OpenAIModels.GPT4, ContextWindow: 8192, MaxOutputTokens: 8192, SupportsTools: true, SupportsSystemPrompt: true},
+	{Provider: ProviderOpenAi, Name: OpenAIModels.GPT4o, ContextWindow: 128000, MaxOutputTokens: 16384, SupportsTools: true, SupportsVision: true, SupportsSystemPrompt: true},
+	{Provider: ProviderOpenAi, Name: OpenAIModels.GPT4Turbo, ContextWindow: 128000, MaxOutputTokens: 4096, SupportsTools: true, SupportsVision: true, SupportsSystemPrompt: true},
+	{Provider: ProviderOpenAi, Name: OpenAIModels.GPT4oMini, ContextWindow: 128000, MaxOutputTokens: 16384, SupportsTools: true, SupportsVision: true, SupportsSystemPrompt: true},
+	{Provider: ProviderOpenAi, Name: OpenAIModels.O1Mini, ContextWindow: 128000, MaxOutputTokens: 65536, Reasoning: true},
+	{Provider: ProviderOpenAi, Name: OpenAIModels.O1, ContextWindow: 200000, MaxOutputTokens: 100000, SupportsTools: true, SupportsVision: true, SupportsSystemPrompt: true, Reasoning: true},
+	{Provider: ProviderOpenAi, Name: OpenAIModels.GPT5, ContextWindow: 400000, MaxOutputTokens: 128000, SupportsTools: true, SupportsVision: true, SupportsSystemPrompt: true, Reasoning: true},
+	{Provider: ProviderOpenAi, Name: OpenAIModels.Codex52, ContextWindow: 400000, MaxOutputTokens: 128000, SupportsTools: true, SupportsSystemPrompt: true, Reasoning: true},
+
+	{Provider: ProviderAnthropic, Name: AnthropicModels.Claude3Haiku, ContextWindow: 200000, MaxOutputTokens: 4096, SupportsTools: true, SupportsVision: true, SupportsSystemPrompt: true},
+	{Provider: ProviderAnthropic, Name: AnthropicModels.Claude35Sonnet, ContextWindow: 200000, MaxOutputTokens: 8192, SupportsTools: true, SupportsVision: true, SupportsSystemPrompt: true},
+	{Provider: ProviderAnthropic, Name: AnthropicModels.Claude37Sonnet, ContextWindow: 200000, MaxOutputTokens: 64000, SupportsTools: true, SupportsVision: true, SupportsSystemPrompt: true},
+	{Provider: ProviderAnthropic, Name: AnthropicModels.Claude4Sonnet, ContextWindow: 200000, MaxOutputTokens: 64000, SupportsTools: true, SupportsVision: true, SupportsSystemPrompt: true},
+	{Provider: ProviderAnthropic, Name: AnthropicModels.Claude4Opus, ContextWindow: 200000, MaxOutputTokens: 32000, SupportsTools: true, SupportsVision: true, SupportsSystemPrompt: true},
+	{Provider: ProviderAnthropic, Name: AnthropicModels.Claude45Sonnet, ContextWindow: 200000, MaxOutputTokens: 64000, SupportsTools: true, SupportsVision: true, SupportsSystemPrompt: true},
+	{Provider: ProviderAnthropic, Name: AnthropicModels.Claude45Opus, ContextWindow: 200000, MaxOutputTokens: 64000, SupportsTools: true, SupportsVision: true, SupportsSystemPrompt: true},
+	{Provider: ProviderAnthropic, Name: AnthropicModels.Claude46Sonnet, ContextWindow: 200000, MaxOutputTokens: 64000, SupportsTools: true, SupportsVision: true, SupportsSystemPrompt: true},
+	{Provider: ProviderAnthropic, Name: AnthropicModels.Claude46Opus, ContextWindow: 200000, MaxOutputTokens: 64000, SupportsTools: true, SupportsVision: true, SupportsSystemPrompt: true},
+
+	{Provider: ProviderGemini, Name: GeminiModels.Gemini20Flash, ContextWindow: 1048576, MaxOutputTokens: 8192, SupportsTools: true, SupportsVision: true, SupportsSystemPrompt: true},
+	{Provider: ProviderGemini, Name: GeminiModels.Gemini20FlashExp, ContextWindow: 1048576, MaxOutputTokens: 8192, SupportsTools: true, SupportsVision: true, SupportsSystemPrompt: true},
+	{Provider: ProviderGemini, Name: GeminiModels.Gemini25Pro, ContextWindow: 1048576, MaxOutputTokens: 65536, SupportsTools: true, SupportsVision: true, SupportsSystemPrompt: true},
+	{Provider: ProviderGemini, Name: GeminiModels.Gemini25Flash, ContextWindow: 1048576, MaxOutputTokens: 65536, SupportsTools: true, SupportsVision: true, SupportsSystemPrompt: true},
+	{Provider: ProviderGemini, Name: GeminiModels.Gemini3Flash, ContextWindow: 1048576, MaxOutputTokens: 65536, SupportsTools: true, SupportsVision: true, SupportsSystemPrompt: true},
+	{Provider: ProviderGemini, Name: GeminiModels.Gemini3Pro, ContextWindow: 1048576, MaxOutputTokens: 65536, SupportsTools: true, SupportsVision: true, SupportsSystemPrompt: true},
+
+	{Provider: ProviderOllama, Name: OllamaModels.Llama3, ContextWindow: 8192, SupportsSystemPrompt: true},
+	{Provider: ProviderOllama, Name: OllamaModels.Llama31, ContextWindow: 131072, SupportsTools: true, SupportsSystemPrompt: true},
+	{Provider: ProviderOllama, Name: OllamaModels.Mistral, ContextWindow: 32768, SupportsTools: true, SupportsSystemPrompt: true},
+	{Provider: ProviderOllama, Name: OllamaModels.Mixtral, ContextWindow: 32768, SupportsTools: true, SupportsSystemPrompt: true},
+	{Provider: ProviderOllama, Name: OllamaModels.Phi3, ContextWindow: 4096, SupportsSystemPrompt: true},
+	{Provider: ProviderOllama, Name: OllamaModels.Gemma2, ContextWindow: 8192, SupportsSystemPrompt: true},
+}
+
+// catalogProvider maps provider aliases onto the provider whose catalog
+// entries they share.
+func catalogProvider(provider string) string {
+	if provider == ProviderAzure {
+		return ProviderOpenAi
+	}
+	return provider
+}
+
+// RegisterModel adds a model to the catalog, or replaces an existing entry,
+// so that it passes NewLLMTask validation and adapters know its limits.
+func RegisterModel(info ModelInfo) error {
+	if info.Name == "" {
+		return fmt.Errorf("%w: model name must not be empty", ErrInvalidConfig)
+	}
+	if _, ok := availableModels[info.Provider]; !ok {
+		return fmt.Errorf("%w: %q", ErrProviderNotFound, info.Provider)
+	}
+	info.Provider = catalogProvider(info.Provider)
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	if catalog[info.Provider] == nil {
+		catalog[info.Provider] = make(map[string]ModelInfo)
+	}
+	catalog[info.Provider][info.Name] = info
+	return nil
+}
+
+// LookupModel returns the catalog entry for a provider's model.
+func LookupModel(provider, model string) (ModelInfo, bool) {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	info, ok := catalog[catalogProvider(provider)][model]
+	return info, ok
+}
+
+// CatalogModels returns the catalog entries of a provider sorted by name.
+func CatalogModels(provider string) []ModelInfo {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	models := make([]ModelInfo, 0, len(catalog[catalogProvider(provider)]))
+	for _, info := range catalog[catalogProvider(provider)] {
+		models = append(models, info)
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].Name < models[j].Name })
+	return models
+}
+
+// catalogOrder is the order in which ContextWindow searches the providers'
+// catalogs, so that a model name registered under several providers always
+// resolves to the same entry.
+var catalogOrder = []string{ProviderOpenAi, ProviderAnthropic, ProviderGemini, ProviderOllama}
+
+// ContextWindow returns the context window of a model in tokens, or 0 if it
+// is not in the catalog. A name registered under several providers resolves
+// to the first of OpenAI, Anthropic, Gemini and Ollama; use LookupModel to
+// pick the provider.
+func ContextWindow(model string) int {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	for _, provider := range catalogOrder {
+		if info, ok := catalog[provider][model]; ok {
+			return info.ContextWindow
+		}
+	}
+	return 0
+}
+
+// modelInfo returns the catalog entry for the config's model. Models missing
+// from the catalog (e.g. custom Ollama models) are assumed to support every
+// feature so that requests pass through unchanged.
+func (c *LLMConfig) modelInfo() ModelInfo {
+	if info, ok := LookupModel(c.provider, c.model); ok {
+		return info
+	}
+	return ModelInfo{
+		Provider:             c.provider,
+		Name:                 c.model,
+		SupportsTools:        true,
+		SupportsVision:       true,
+		SupportsSystemPrompt: true,
+	}
+}
+
+// outputTokens returns the configured max tokens clamped to the model's
+// output limit.
+func (c *LLMConfig) outputTokens() int {
+	if limit := c.modelInfo().MaxOutputTokens; limit > 0 && c.maxTokens > limit {
+		return limit
+	}
+	return c.maxTokens
+}
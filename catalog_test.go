@@ -0,0 +1,184 @@
+package forza
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+func TestCatalog_CoversPredefinedModels(t *testing.T) {
+	lists := map[string]Models{
+		ProviderOpenAi:    OpenAIModels,
+		ProviderAnthropic: AnthropicModels,
+		ProviderGemini:    GeminiModels,
+		ProviderOllama:    OllamaModels,
+	}
+	for provider, list := range lists {
+		for _, model := range list.ListModels() {
+			if _, ok := LookupModel(provider, model); !ok {
+				t.Errorf("missing catalog entry for %s/%s", provider, model)
+			}
+		}
+	}
+}
+
+func TestLookupModel_AzureSharesOpenAI(t *testing.T) {
+	info, ok := LookupModel(ProviderAzure, OpenAIModels.GPT4o)
+	if !ok {
+		t.Fatal("expected Azure to share OpenAI catalog entries")
+	}
+	if info.Provider != ProviderOpenAi {
+		t.Errorf("expected provider %q, got %q", ProviderOpenAi, info.Provider)
+	}
+}
+
+func TestRegisterModel(t *testing.T) {
+	err := RegisterModel(ModelInfo{
+		Provider:             ProviderAnthropic,
+		Name:                 "claude-test-snapshot",
+		ContextWindow:        100000,
+		MaxOutputTokens:      2048,
+		SupportsTools:        true,
+		SupportsSystemPrompt: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	agent := NewAgent().WithRole("r").WithBackstory("b").WithGoal("g")
+	config := NewLLMConfig().WithProvider(ProviderAnthropic).WithModel("claude-test-snapshot")
+	if _, err := agent.NewLLMTask(config); err != nil {
+		t.Errorf("expected registered model to pass validation, got %v", err)
+	}
+	if got := ContextWindow("claude-test-snapshot"); got != 100000 {
+		t.Errorf("expected context window 100000, got %d", got)
+	}
+
+	found := false
+	for _, info := range CatalogModels(ProviderAnthropic) {
+		if info.Name == "claude-test-snapshot" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected registered model in CatalogModels")
+	}
+}
+
+func TestContextWindow_SharedNameIsDeterministic(t *testing.T) {
+	RegisterModel(ModelInfo{Provider: ProviderOllama, Name: "shared-test-model", ContextWindow: 8192})
+	RegisterModel(ModelInfo{Provider: ProviderAnthropic, Name: "shared-test-model", ContextWindow: 100000})
+	for i := 0; i < 20; i++ {
+		if got := ContextWindow("shared-test-model"); got != 100000 {
+			t.Fatalf("expected the Anthropic entry before the Ollama one, got %d", got)
+		}
+	}
+	if info, _ := LookupModel(ProviderOllama, "shared-test-model"); info.ContextWindow != 8192 {
+		t.Errorf("expected LookupModel to pick the provider, got %d", info.ContextWindow)
+	}
+}
+
+func TestRegisterModel_Invalid(t *testing.T) {
+	if err := RegisterModel(ModelInfo{Provider: ProviderOpenAi}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for empty name, got %v", err)
+	}
+	if err := RegisterModel(ModelInfo{Provider: "nope", Name: "m"}); !errors.Is(err, ErrProviderNotFound) {
+		t.Errorf("expected ErrProviderNotFound, got %v", err)
+	}
+}
+
+func TestCatalogModels_Sorted(t *testing.T) {
+	models := CatalogModels(ProviderGemini)
+	for i := 1; i < len(models); i++ {
+		if models[i-1].Name > models[i].Name {
+			t.Fatalf("expected sorted models, got %q before %q", models[i-1].Name, models[i].Name)
+		}
+	}
+}
+
+func TestLLMConfig_OutputTokens(t *testing.T) {
+	c := NewLLMConfig().WithProvider(ProviderAnthropic).WithModel(AnthropicModels.Claude3Haiku).WithMaxTokens(10000)
+	if got := c.outputTokens(); got != 4096 {
+		t.Errorf("expected max tokens clamped to 4096, got %d", got)
+	}
+
+	c = NewLLMConfig().WithProvider(ProviderOllama).WithModel("my-custom-model").WithMaxTokens(10000)
+	if got := c.outputTokens(); got != 10000 {
+		t.Errorf("expected unclamped max tokens for unknown model, got %d", got)
+	}
+	if info := c.modelInfo(); !info.SupportsTools || !info.SupportsSystemPrompt {
+		t.Errorf("expected unknown models to be assumed fully capable, got %+v", info)
+	}
+}
+
+// captureOpenAIRequest runs a completion against a mock server and returns
+// the raw JSON request body.
+func captureOpenAIRequest(t *testing.T, model string) map[string]any {
+	t.Helper()
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: "ok"}}},
+		})
+	}))
+	defer server.Close()
+
+	task := newTestOpenAITask(server.URL)
+	task.(*openaiProvider).config.WithModel(model)
+	task.WithUserPrompt("hello")
+	task.AddCustomTools("lookup", "desc", NewFunction(WithProperty("q", "query", true)), func(string) (string, error) {
+		return "", nil
+	})
+
+	if _, err := task.Completion(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return body
+}
+
+func TestOpenAI_Completion_ReasoningModelParams(t *testing.T) {
+	body := captureOpenAIRequest(t, OpenAIModels.GPT5)
+
+	if _, ok := body["temperature"]; ok {
+		t.Error("expected no temperature for a reasoning model")
+	}
+	if _, ok := body["max_tokens"]; ok {
+		t.Error("expected no max_tokens for a reasoning model")
+	}
+	if body["max_completion_tokens"] != float64(100) {
+		t.Errorf("expected max_completion_tokens 100, got %v", body["max_completion_tokens"])
+	}
+	if _, ok := body["tools"]; !ok {
+		t.Error("expected tools for a tool-capable model")
+	}
+}
+
+func TestOpenAI_Completion_StandardModelParams(t *testing.T) {
+	body := captureOpenAIRequest(t, OpenAIModels.GPT4oMini)
+
+	if body["max_tokens"] != float64(100) {
+		t.Errorf("expected max_tokens 100, got %v", body["max_tokens"])
+	}
+	if _, ok := body["temperature"]; !ok {
+		t.Error("expected temperature for a standard model")
+	}
+}
+
+func TestOpenAI_Completion_LimitedModelDropsToolsAndSystem(t *testing.T) {
+	body := captureOpenAIRequest(t, OpenAIModels.O1Mini)
+
+	if _, ok := body["tools"]; ok {
+		t.Error("expected tools to be dropped for a model without tool support")
+	}
+	for _, m := range body["messages"].([]any) {
+		if role := m.(map[string]any)["role"]; role == "system" {
+			t.Error("expected no system messages for a model without system prompt support")
+		}
+	}
+}
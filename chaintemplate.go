@@ -0,0 +1,208 @@
+package forza
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// TemplateStep is one step of a TemplateChain. Prompt is a text/template
+// rendered as the agent's user prompt; it refers to pipeline inputs and to
+// the outputs of earlier steps by name, e.g. "Edit {{.draft}} for {{.audience}}".
+// The step's own output is bound to Name.
+type TemplateStep struct {
+	Name   string
+	Agent  LLMAgent
+	Prompt string
+}
+
+// TemplateChain runs agents in order, rendering each step's prompt from the
+// pipeline inputs and the named outputs of the steps before it. Unlike
+// CreateChain, a step can use any earlier output and decides where it goes
+// in the prompt; nothing is appended as context.
+//
+// Each run sets the agents' user prompts, so an agent must not be shared
+// with another run in progress.
+type TemplateChain struct {
+	pipeline *Pipeline
+	steps    []templateStep
+	inputs   []string
+}
+
+type templateStep struct {
+	TemplateStep
+	tmpl *template.Template
+}
+
+var templateNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// CreateTemplateChain parses and checks the steps' templates. Every variable
+// a template uses must be produced by an earlier step or be a pipeline input;
+// the inputs are the variables no step produces, and Run rejects a call that
+// leaves any of them out before making a request.
+func (p *Pipeline) CreateTemplateChain(steps ...TemplateStep) (*TemplateChain, error) {
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("%w: at least one step is required", ErrInvalidTemplate)
+	}
+
+	produced := make(map[string]int, len(steps))
+	for i, s := range steps {
+		if !templateNamePattern.MatchString(s.Name) {
+			return nil, fmt.Errorf("%w: step %d: name %q is not a valid template identifier", ErrInvalidTemplate, i+1, s.Name)
+		}
+		if _, dup := produced[s.Name]; dup {
+			return nil, fmt.Errorf("%w: duplicate step name %q", ErrInvalidTemplate, s.Name)
+		}
+		if s.Agent == nil {
+			return nil, fmt.Errorf("%w: step %q has no agent", ErrNilTask, s.Name)
+		}
+		produced[s.Name] = i
+	}
+
+	c := &TemplateChain{pipeline: p}
+	inputs := make(map[string]bool)
+	for i, s := range steps {
+		tmpl, err := template.New(s.Name).Option("missingkey=error").Parse(s.Prompt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: step %q: %v", ErrInvalidTemplate, s.Name, err)
+		}
+		for _, name := range templateVariables(tmpl) {
+			at, ok := produced[name]
+			switch {
+			case !ok:
+				inputs[name] = true
+			case at >= i:
+				return nil, fmt.Errorf("%w: step %q uses %q before it is produced", ErrMissingVariable, s.Name, name)
+			}
+		}
+		c.steps = append(c.steps, templateStep{TemplateStep: s, tmpl: tmpl})
+	}
+	for name := range inputs {
+		c.inputs = append(c.inputs, name)
+	}
+	sort.Strings(c.inputs)
+	return c, nil
+}
+
+// Inputs returns the names of the variables Run must be given, sorted.
+func (c *TemplateChain) Inputs() []string {
+	return append([]string(nil), c.inputs...)
+}
+
+// Run executes the steps in order and returns the inputs together with every
+// step's output, keyed by name; the last step's output is the chain's result.
+// On failure it returns the values gathered so far.
+//
+// When the pipeline or ctx carries a Blackboard, inputs missing from the map
+// are read from it as Run starts.
+func (c *TemplateChain) Run(ctx context.Context, inputs map[string]string) (map[string]string, error) {
+	ctx = c.pipeline.runContext(ctx)
+	vars := make(map[string]string, len(inputs)+len(c.steps))
+	var missing []string
+	for _, name := range c.inputs {
+		if _, ok := inputs[name]; ok {
+			continue
+		}
+		if b := BlackboardFromContext(ctx); b != nil {
+			if v, ok := b.Get(name); ok {
+				vars[name] = v
+				continue
+			}
+		}
+		missing = append(missing, name)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrMissingVariable, strings.Join(missing, ", "))
+	}
+
+	for k, v := range inputs {
+		vars[k] = v
+	}
+	for i, s := range c.steps {
+		c.pipeline.logDebug("chain progress", "step", s.Name, "task", i+1, "total", len(c.steps))
+
+		var prompt strings.Builder
+		if err := s.tmpl.Execute(&prompt, vars); err != nil {
+			return vars, fmt.Errorf("%w: step %q: %v", ErrInvalidTemplate, s.Name, err)
+		}
+		s.Agent.WithUserPrompt(prompt.String())
+		output, err := s.Agent.Completion(namedTaskContext(ctx, s.Name))
+		if err != nil {
+			return vars, fmt.Errorf("%w: step %q failed: %w", ErrChainInterrupted, s.Name, err)
+		}
+		vars[s.Name] = output
+	}
+	return vars, nil
+}
+
+// templateVariables returns the top-level fields a template reads from its
+// data, such as "draft" for {{.draft}} or {{$.draft}}. Fields inside range
+// and with blocks are relative to a different dot and are left out.
+func templateVariables(tmpl *template.Template) []string {
+	seen := make(map[string]bool)
+	var names []string
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	var walk func(n parse.Node, dotIsData bool)
+	walk = func(n parse.Node, dotIsData bool) {
+		switch n := n.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child, dotIsData)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe, dotIsData)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				walk(cmd, dotIsData)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg, dotIsData)
+			}
+		case *parse.FieldNode:
+			if dotIsData {
+				add(n.Ident[0])
+			}
+		case *parse.VariableNode:
+			if n.Ident[0] == "$" && len(n.Ident) > 1 {
+				add(n.Ident[1])
+			}
+		case *parse.ChainNode:
+			walk(n.Node, dotIsData)
+		case *parse.IfNode:
+			walk(n.Pipe, dotIsData)
+			walk(n.List, dotIsData)
+			walk(n.ElseList, dotIsData)
+		case *parse.RangeNode:
+			walk(n.Pipe, dotIsData)
+			walk(n.List, false)
+			walk(n.ElseList, dotIsData)
+		case *parse.WithNode:
+			walk(n.Pipe, dotIsData)
+			walk(n.List, false)
+			walk(n.ElseList, dotIsData)
+		case *parse.TemplateNode:
+			walk(n.Pipe, dotIsData)
+		}
+	}
+	if tmpl.Tree != nil {
+		walk(tmpl.Tree.Root, true)
+	}
+	return names
+}
@@ -0,0 +1,100 @@
+package forza
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+// promptEcho is a stubAgent that answers with a prefix and the prompt it was given.
+func promptEcho(prefix string) *stubAgent {
+	a := &stubAgent{}
+	a.complete = func(ctx context.Context, params ...string) (string, error) {
+		if len(params) > 0 {
+			return "", errors.New("unexpected context argument")
+		}
+		return prefix + "(" + a.prompt + ")", nil
+	}
+	return a
+}
+
+func TestTemplateChain_Run(t *testing.T) {
+	researcher := promptEcho("facts")
+	writer := promptEcho("draft")
+	editor := promptEcho("final")
+
+	chain, err := NewPipeline().CreateTemplateChain(
+		TemplateStep{Name: "research", Agent: researcher, Prompt: "Research {{.topic}}"},
+		TemplateStep{Name: "draft", Agent: writer, Prompt: "Write for {{.audience}} using {{.research}}"},
+		TemplateStep{Name: "final", Agent: editor, Prompt: "Check {{.draft}} against {{$.research}}{{if .audience}} for {{.audience}}{{end}}"},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(chain.Inputs(), ","); got != "audience,topic" {
+		t.Errorf("expected the unproduced variables as inputs, got %s", got)
+	}
+
+	out, err := chain.Run(context.Background(), map[string]string{"topic": "Go", "audience": "kids"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if editor.prompt != "Check draft(Write for kids using facts(Research Go)) against facts(Research Go) for kids" {
+		t.Errorf("unexpected final prompt %q", editor.prompt)
+	}
+	if out["final"] != "final("+editor.prompt+")" || out["topic"] != "Go" {
+		t.Errorf("unexpected outputs %v", out)
+	}
+}
+
+func TestTemplateChain_MissingInputsBeforeAnyCall(t *testing.T) {
+	first := promptEcho("a")
+	chain, err := NewPipeline().CreateTemplateChain(
+		TemplateStep{Name: "a", Agent: first, Prompt: "{{.topic}}"},
+		TemplateStep{Name: "b", Agent: promptEcho("b"), Prompt: "{{.a}} {{.style}}"},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = chain.Run(context.Background(), map[string]string{})
+	if !errors.Is(err, ErrMissingVariable) || !strings.Contains(err.Error(), "style, topic") {
+		t.Errorf("expected both missing inputs, got %v", err)
+	}
+	if first.calls != 0 {
+		t.Errorf("expected no completion before the inputs are checked, got %d", first.calls)
+	}
+}
+
+func TestCreateTemplateChain_Errors(t *testing.T) {
+	a := promptEcho("a")
+	tests := []struct {
+		name  string
+		steps []TemplateStep
+		want  error
+	}{
+		{"no steps", nil, ErrInvalidTemplate},
+		{"forward reference", []TemplateStep{{Name: "a", Agent: a, Prompt: "{{.b}}"}, {Name: "b", Agent: a, Prompt: "x"}}, ErrMissingVariable},
+		{"self reference", []TemplateStep{{Name: "a", Agent: a, Prompt: "{{.a}}"}}, ErrMissingVariable},
+		{"bad template", []TemplateStep{{Name: "a", Agent: a, Prompt: "{{.a"}}, ErrInvalidTemplate},
+		{"bad name", []TemplateStep{{Name: "my-step", Agent: a, Prompt: "x"}}, ErrInvalidTemplate},
+		{"duplicate", []TemplateStep{{Name: "a", Agent: a, Prompt: "x"}, {Name: "a", Agent: a, Prompt: "y"}}, ErrInvalidTemplate},
+		{"nil agent", []TemplateStep{{Name: "a", Prompt: "x"}}, ErrNilTask},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPipeline().CreateTemplateChain(tt.steps...); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestTemplateVariables_IgnoresRangeAndWithBodies(t *testing.T) {
+	tmpl := template.Must(template.New("t").Parse(`{{with .a}}{{.inner}}{{else}}{{.b}}{{end}}{{range .c}}{{.item}}{{end}}{{$.d}}{{len .e | printf "%d"}}`))
+	if got := strings.Join(templateVariables(tmpl), ","); got != "a,b,c,d,e" {
+		t.Errorf("unexpected variables %s", got)
+	}
+}
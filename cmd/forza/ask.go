@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/vitoraguila/forza"
+)
+
+// askResult is the -json output of ask.
+type askResult struct {
+	Provider         string  `json:"provider"`
+	Model            string  `json:"model"`
+	Answer           string  `json:"answer"`
+	PromptTokens     int     `json:"promptTokens"`
+	CompletionTokens int     `json:"completionTokens"`
+	CostUSD          float64 `json:"costUSD"`
+}
+
+// runAsk sends one prompt. The prompt comes from the arguments; piped stdin
+// becomes the prompt's context, or the prompt itself when no arguments are
+// given.
+func runAsk(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("forza ask", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var m modelFlags
+	m.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return parseExit(err)
+	}
+
+	prompt := strings.Join(fs.Args(), " ")
+	var params []string
+	if isPiped(stdin) {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return fail(stderr, err)
+		}
+		if piped := strings.TrimSpace(string(data)); piped != "" {
+			if prompt == "" {
+				prompt = piped
+			} else {
+				params = append(params, piped)
+			}
+		}
+	}
+	if prompt == "" {
+		fmt.Fprintln(stderr, "forza ask: no prompt given")
+		return exitUsage
+	}
+
+	config, err := m.llmConfig()
+	if err != nil {
+		return fail(stderr, err)
+	}
+	task, err := m.agent().NewLLMTask(config)
+	if err != nil {
+		return fail(stderr, err)
+	}
+	task.WithUserPrompt(prompt)
+
+	tracker := forza.NewCostTracker()
+	ctx, cancel := context.WithTimeout(forza.ContextWithCostTracker(context.Background(), tracker), m.timeout)
+	defer cancel()
+
+	answer, err := task.Completion(ctx, params...)
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	if m.json {
+		usage := tracker.Usage()
+		err = writeJSON(stdout, askResult{
+			Provider:         config.Provider(),
+			Model:            config.Model(),
+			Answer:           answer,
+			PromptTokens:     usage.PromptTokens,
+			CompletionTokens: usage.CompletionTokens,
+			CostUSD:          tracker.Cost(),
+		})
+	} else {
+		_, err = fmt.Fprintln(stdout, answer)
+	}
+	if err != nil {
+		return fail(stderr, err)
+	}
+	return exitOK
+}
+
+// parseExit maps a flag parsing error to an exit code; -h is not an error.
+func parseExit(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	return exitUsage
+}
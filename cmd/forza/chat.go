@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/vitoraguila/forza"
+)
+
+// chatTurn is one exchange of a chat session; it is also the -json output.
+type chatTurn struct {
+	Prompt string `json:"prompt"`
+	Answer string `json:"answer"`
+}
+
+// runChat reads prompts line by line and answers each with the previous
+// turns as context. "/reset" clears the history and "/exit" ends the session.
+func runChat(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("forza chat", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var m modelFlags
+	m.register(fs)
+	maxTurns := fs.Int("history", 20, "number of previous turns sent as context")
+	if err := fs.Parse(args); err != nil {
+		return parseExit(err)
+	}
+
+	config, err := m.llmConfig()
+	if err != nil {
+		return fail(stderr, err)
+	}
+	// Drop the oldest turns rather than fail once the transcript outgrows
+	// the context window.
+	config.WithContextFit(forza.ContextFitDropOldest)
+	task, err := m.agent().NewLLMTask(config)
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	interactive := !isPiped(stdin)
+	if interactive {
+		fmt.Fprintf(stderr, "Chatting with %s/%s. Type /reset to clear the history, /exit to quit.\n", config.Provider(), config.Model())
+	}
+
+	var history []chatTurn
+	code := exitOK
+	scanner := bufio.NewScanner(stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for {
+		if interactive {
+			fmt.Fprint(stderr, "> ")
+		}
+		if !scanner.Scan() {
+			break
+		}
+		line := strings.TrimSpace(scanner.Text())
+		switch line {
+		case "":
+			continue
+		case "/exit", "/quit":
+			return code
+		case "/reset":
+			history = nil
+			continue
+		}
+
+		var params []string
+		if len(history) > 0 {
+			params = append(params, transcript(history))
+		}
+		task.WithUserPrompt(line)
+
+		ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+		answer, err := task.Completion(ctx, params...)
+		cancel()
+		if err != nil {
+			// Keep the session alive, but report the failure in the exit code.
+			fmt.Fprintf(stderr, "forza: %v\n", err)
+			code = exitError
+			continue
+		}
+
+		turn := chatTurn{Prompt: line, Answer: answer}
+		history = append(history, turn)
+		if *maxTurns >= 0 && len(history) > *maxTurns {
+			history = history[len(history)-*maxTurns:]
+		}
+
+		if m.json {
+			err = writeJSON(stdout, turn)
+		} else {
+			_, err = fmt.Fprintln(stdout, answer)
+		}
+		if err != nil {
+			return fail(stderr, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fail(stderr, err)
+	}
+	return code
+}
+
+// transcript renders previous turns as prompt context.
+func transcript(history []chatTurn) string {
+	var b strings.Builder
+	b.WriteString("the conversation so far:")
+	for _, t := range history {
+		fmt.Fprintf(&b, "\nUser: %s\nAssistant: %s", t.Prompt, t.Answer)
+	}
+	return b.String()
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/vitoraguila/forza"
+)
+
+// modelFlags selects the model and agent persona for ask and chat.
+type modelFlags struct {
+	provider  string
+	model     string
+	endpoint  string
+	config    string
+	profile   string
+	role      string
+	backstory string
+	goal      string
+	timeout   time.Duration
+	json      bool
+}
+
+func (m *modelFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&m.provider, "provider", os.Getenv("FORZA_PROVIDER"), "provider: openai, openai-azure, anthropic, gemini or ollama")
+	fs.StringVar(&m.model, "model", os.Getenv("FORZA_MODEL"), "model name")
+	fs.StringVar(&m.endpoint, "endpoint", os.Getenv("FORZA_ENDPOINT"), "endpoint for Azure OpenAI or Ollama")
+	fs.StringVar(&m.config, "config", "", "profiles file (YAML or JSON); overrides -provider and -model")
+	fs.StringVar(&m.profile, "profile", "", "profile to use from -config (default: the file's default)")
+	fs.StringVar(&m.role, "role", "helpful assistant", "agent role")
+	fs.StringVar(&m.backstory, "backstory", "you answer questions accurately and concisely", "agent backstory")
+	fs.StringVar(&m.goal, "goal", "helping the user", "agent goal")
+	fs.DurationVar(&m.timeout, "timeout", 2*time.Minute, "timeout for each completion")
+	fs.BoolVar(&m.json, "json", false, "print JSON")
+}
+
+// providerKeyEnv is the conventional API key variable of each provider.
+var providerKeyEnv = map[string]string{
+	forza.ProviderOpenAi:    "OPENAI_API_KEY",
+	forza.ProviderAzure:     "AZURE_OPENAI_API_KEY",
+	forza.ProviderAnthropic: "ANTHROPIC_API_KEY",
+	forza.ProviderGemini:    "GEMINI_API_KEY",
+}
+
+// llmConfig builds the config from -config/-profile or from the flags.
+func (m *modelFlags) llmConfig() (*forza.LLMConfig, error) {
+	if m.config != "" {
+		return forza.LoadProfile(m.config, m.profile)
+	}
+	if m.provider == "" || m.model == "" {
+		return nil, fmt.Errorf("-provider and -model (or FORZA_PROVIDER and FORZA_MODEL) are required")
+	}
+	return providerProfile(m.provider, m.model, m.endpoint).Config()
+}
+
+// providerProfile describes a provider's credentials the way the CLI finds
+// them: FORZA_API_KEY first, then the provider's usual variable.
+func providerProfile(provider, model, endpoint string) forza.Profile {
+	p := forza.Profile{Provider: provider, Model: model, Endpoint: endpoint}
+	if provider == forza.ProviderOllama {
+		return p
+	}
+	p.APIKeyEnv = "FORZA_API_KEY"
+	if os.Getenv(p.APIKeyEnv) == "" && providerKeyEnv[provider] != "" {
+		p.APIKeyEnv = providerKeyEnv[provider]
+	}
+	return p
+}
+
+func (m *modelFlags) agent() *forza.Agent {
+	return forza.NewAgent().
+		WithRole(m.role).
+		WithBackstory(m.backstory).
+		WithGoal(m.goal)
+}
@@ -0,0 +1,99 @@
+// Command forza runs LLM agents from the command line.
+//
+// Usage:
+//
+//	forza ask [flags] [prompt]     one-shot completion
+//	forza chat [flags]             interactive session with history
+//	forza run [flags] FILE         execute a declarative pipeline file
+//	forza models [flags]           list catalog or live models
+//	forza tools [flags] [NAME]     list or invoke a built-in tool
+//
+// Models are selected with -provider/-model (defaulting to FORZA_PROVIDER and
+// FORZA_MODEL) or with -config/-profile. API keys are read from FORZA_API_KEY
+// or the provider's usual variable (OPENAI_API_KEY, ANTHROPIC_API_KEY,
+// GEMINI_API_KEY). Every command accepts -json for machine-readable output,
+// and prompts or tool input can be piped through stdin.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Exit codes.
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdin io.Reader, stdout, stderr io.Writer) int
+}
+
+var commands = []command{
+	{"ask", "send a single prompt and print the answer", runAsk},
+	{"chat", "start an interactive chat session", runChat},
+	{"run", "execute a declarative pipeline file", runPipeline},
+	{"models", "list catalog models, or live models with -live", runModels},
+	{"tools", "list built-in tools or invoke one", runTools},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run dispatches to a subcommand and returns the process exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+		usage(stderr)
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(args[1:], stdin, stdout, stderr)
+		}
+	}
+	fmt.Fprintf(stderr, "forza: unknown command %q\n\n", args[0])
+	usage(stderr)
+	return exitUsage
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: forza <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "forza <command> -h" for the flags of a command.`)
+}
+
+// fail prints err and returns exitError.
+func fail(stderr io.Writer, err error) int {
+	fmt.Fprintf(stderr, "forza: %v\n", err)
+	return exitError
+}
+
+// writeJSON prints v as one line of JSON.
+func writeJSON(w io.Writer, v any) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+// isPiped reports whether r carries piped data rather than a terminal.
+func isPiped(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return r != nil
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
+}
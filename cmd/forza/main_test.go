@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/vitoraguila/forza"
+)
+
+// mockOllama answers chat completions with the user message it received and
+// records every request.
+func mockOllama(t *testing.T) (*httptest.Server, *[]openai.ChatCompletionRequest) {
+	t.Helper()
+	var requests []openai.ChatCompletionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openai.ChatCompletionRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+		json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: "re: " + req.Messages[len(req.Messages)-1].Content}}},
+			Usage:   openai.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func runCLI(stdin string, args ...string) (code int, stdout, stderr string) {
+	var out, errOut bytes.Buffer
+	code = run(args, strings.NewReader(stdin), &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+func TestRun_Usage(t *testing.T) {
+	if code, _, stderr := runCLI(""); code != exitUsage || !strings.Contains(stderr, "Commands:") {
+		t.Errorf("expected usage and exit %d, got %d: %s", exitUsage, code, stderr)
+	}
+	if code, _, stderr := runCLI("", "frobnicate"); code != exitUsage || !strings.Contains(stderr, `unknown command "frobnicate"`) {
+		t.Errorf("expected an unknown command error, got %d: %s", code, stderr)
+	}
+	if code, _, _ := runCLI("", "ask", "-h"); code != exitOK {
+		t.Errorf("expected -h to exit %d, got %d", exitOK, code)
+	}
+}
+
+func TestAsk(t *testing.T) {
+	server, requests := mockOllama(t)
+	flags := []string{"ask", "-provider", forza.ProviderOllama, "-model", "llama3.1", "-endpoint", server.URL + "/v1"}
+
+	code, stdout, stderr := runCLI("", append(flags, "what", "is", "go?")...)
+	if code != exitOK {
+		t.Fatalf("exit %d: %s", code, stderr)
+	}
+	if stdout != "re: what is go?\n" {
+		t.Errorf("unexpected output %q", stdout)
+	}
+
+	// Piped stdin is the context when a prompt is given...
+	runCLI("some notes", append(flags, "summarize")...)
+	if got := (*requests)[1].Messages[2].Content; !strings.HasPrefix(got, "summarize") || !strings.HasSuffix(got, "some notes") {
+		t.Errorf("expected stdin as context, got %q", got)
+	}
+
+	// ...and the prompt itself otherwise.
+	code, stdout, _ = runCLI("from stdin", append(flags, "-json")...)
+	if code != exitOK {
+		t.Fatalf("exit %d", code)
+	}
+	var result askResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("invalid JSON %q: %v", stdout, err)
+	}
+	if result.Answer != "re: from stdin" || result.Provider != forza.ProviderOllama || result.PromptTokens != 10 || result.CompletionTokens != 5 {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
+
+func TestAsk_Errors(t *testing.T) {
+	t.Setenv("FORZA_PROVIDER", "")
+	t.Setenv("FORZA_MODEL", "")
+	if code, _, stderr := runCLI("", "ask", "hello"); code != exitError || !strings.Contains(stderr, "-provider and -model") {
+		t.Errorf("expected a missing model error, got %d: %s", code, stderr)
+	}
+	if code, _, _ := runCLI("", "ask", "-provider", "ollama", "-model", "x"); code != exitUsage {
+		t.Errorf("expected exit %d without a prompt, got %d", exitUsage, code)
+	}
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	t.Setenv("FORZA_API_KEY", "")
+	code, _, stderr := runCLI("", "ask", "-provider", forza.ProviderAnthropic, "-model", forza.AnthropicModels.Claude4Sonnet, "hi")
+	if code != exitError || !strings.Contains(stderr, "ANTHROPIC_API_KEY") {
+		t.Errorf("expected a missing key error naming ANTHROPIC_API_KEY, got %d: %s", code, stderr)
+	}
+}
+
+func TestChat_History(t *testing.T) {
+	server, requests := mockOllama(t)
+
+	input := "hello\nhow are you\n/reset\nagain\n/exit\nignored\n"
+	code, stdout, stderr := runCLI(input, "chat", "-json", "-provider", forza.ProviderOllama, "-model", "llama3.1", "-endpoint", server.URL+"/v1")
+	if code != exitOK {
+		t.Fatalf("exit %d: %s", code, stderr)
+	}
+	if lines := strings.Split(strings.TrimSpace(stdout), "\n"); len(lines) != 3 {
+		t.Fatalf("expected 3 JSON turns, got %q", stdout)
+	}
+	if len(*requests) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(*requests))
+	}
+
+	second := (*requests)[1].Messages[2].Content
+	if !strings.Contains(second, "User: hello\nAssistant: re: hello") {
+		t.Errorf("expected the first turn in the second prompt, got %q", second)
+	}
+	if third := (*requests)[2].Messages[2].Content; third != "again" {
+		t.Errorf("expected /reset to clear the history, got %q", third)
+	}
+}
+
+func TestRunPipeline(t *testing.T) {
+	server, _ := mockOllama(t)
+	def := fmt.Sprintf(`
+profiles:
+  local: {provider: ollama, model: llama3.1, endpoint: %s/v1}
+agents:
+  a: {role: r, backstory: b, goal: g}
+tasks:
+  one: {agent: a, prompt: first}
+  two: {agent: a, prompt: second}
+pipeline:
+  mode: sequential
+  tasks: [one, two]
+`, server.URL)
+	path := filepath.Join(t.TempDir(), "pipeline.yaml")
+	if err := os.WriteFile(path, []byte(def), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	code, stdout, stderr := runCLI("", "run", "-json", path)
+	if code != exitOK {
+		t.Fatalf("exit %d: %s", code, stderr)
+	}
+	var result pipelineResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("invalid JSON %q: %v", stdout, err)
+	}
+	if strings.Join(result.Tasks, ",") != "one,two" || strings.Join(result.Results, ",") != "re: first,re: second" {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	chain := strings.Replace(def, "mode: sequential", "mode: chain", 1)
+	if err := os.WriteFile(path, []byte(chain), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	code, stdout, stderr = runCLI("the topic", "run", "-json", path)
+	if code != exitOK {
+		t.Fatalf("exit %d: %s", code, stderr)
+	}
+	result = pipelineResult{}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("invalid JSON %q: %v", stdout, err)
+	}
+	if strings.Join(result.Tasks, ",") != "two" || len(result.Results) != 1 || !strings.HasPrefix(result.Results[0], "re: second") {
+		t.Errorf("expected the chain's last task and final result, got %+v", result)
+	}
+	if !strings.HasSuffix(result.Results[0], "context: the topic") {
+		t.Errorf("expected piped stdin as the first task's context, got %q", result.Results[0])
+	}
+
+	if code, _, _ := runCLI("", "run"); code != exitUsage {
+		t.Errorf("expected exit %d without a file, got %d", exitUsage, code)
+	}
+}
+
+func TestModels(t *testing.T) {
+	code, stdout, _ := runCLI("", "models", "-provider", forza.ProviderAnthropic, "-json")
+	if code != exitOK {
+		t.Fatalf("exit %d", code)
+	}
+	var models []forza.ModelInfo
+	if err := json.Unmarshal([]byte(stdout), &models); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(models) != len(forza.CatalogModels(forza.ProviderAnthropic)) || models[0].Provider != forza.ProviderAnthropic {
+		t.Errorf("unexpected models %+v", models)
+	}
+
+	code, stdout, _ = runCLI("", "models")
+	if code != exitOK || !strings.Contains(stdout, forza.OpenAIModels.GPT4oMini) || !strings.Contains(stdout, forza.GeminiModels.Gemini25Pro) {
+		t.Errorf("expected every provider in the table, got %d: %s", code, stdout)
+	}
+}
+
+func TestModels_Live(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"models":[{"name":"llama3:latest"}]}`))
+	}))
+	defer server.Close()
+
+	code, stdout, stderr := runCLI("", "models", "-live", "-provider", forza.ProviderOllama, "-endpoint", server.URL)
+	if code != exitOK || stdout != "llama3:latest\n" {
+		t.Errorf("unexpected result %d %q: %s", code, stdout, stderr)
+	}
+}
+
+func TestTools(t *testing.T) {
+	code, stdout, _ := runCLI("", "tools", "-json")
+	if code != exitOK {
+		t.Fatalf("exit %d", code)
+	}
+	var infos []toolInfo
+	if err := json.Unmarshal([]byte(stdout), &infos); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(infos) != 1 || infos[0].Name != "web_scraper" || strings.Contains(infos[0].Description, "\n") {
+		t.Errorf("unexpected tools %+v", infos)
+	}
+
+	if code, _, _ := runCLI("", "tools", "nope"); code != exitUsage {
+		t.Errorf("expected exit %d for an unknown tool, got %d", exitUsage, code)
+	}
+	if code, _, _ := runCLI("", "tools", "web_scraper"); code != exitUsage {
+		t.Errorf("expected exit %d without input, got %d", exitUsage, code)
+	}
+	if code, _, stderr := runCLI("not a url", "tools", "web_scraper"); code != exitError || !strings.Contains(stderr, "scraper") {
+		t.Errorf("expected the scraper to reject stdin input, got %d: %s", code, stderr)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/vitoraguila/forza"
+)
+
+// catalogProviders are the providers listed when -provider is not given.
+var catalogProviders = []string{
+	forza.ProviderOpenAi,
+	forza.ProviderAnthropic,
+	forza.ProviderGemini,
+	forza.ProviderOllama,
+}
+
+// runModels prints the model catalog, or with -live the models the
+// provider's credentials can access.
+func runModels(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("forza models", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	provider := fs.String("provider", os.Getenv("FORZA_PROVIDER"), "only list this provider's models")
+	endpoint := fs.String("endpoint", os.Getenv("FORZA_ENDPOINT"), "endpoint for Azure OpenAI or Ollama (with -live)")
+	live := fs.Bool("live", false, "ask the provider instead of reading the catalog")
+	timeout := fs.Duration("timeout", 30*time.Second, "timeout for -live")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if err := fs.Parse(args); err != nil {
+		return parseExit(err)
+	}
+
+	if *live {
+		if *provider == "" {
+			fmt.Fprintln(stderr, "forza models: -live requires -provider")
+			return exitUsage
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+
+		models, err := forza.ListModels(ctx, liveConfig(*provider, *endpoint))
+		if err != nil {
+			return fail(stderr, err)
+		}
+		if *asJSON {
+			err = writeJSON(stdout, models)
+		} else {
+			for _, m := range models {
+				if _, err = fmt.Fprintln(stdout, m); err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			return fail(stderr, err)
+		}
+		return exitOK
+	}
+
+	providers := catalogProviders
+	if *provider != "" {
+		providers = []string{*provider}
+	}
+	var models []forza.ModelInfo
+	for _, p := range providers {
+		models = append(models, forza.CatalogModels(p)...)
+	}
+
+	if *asJSON {
+		if models == nil {
+			models = []forza.ModelInfo{}
+		}
+		if err := writeJSON(stdout, models); err != nil {
+			return fail(stderr, err)
+		}
+		return exitOK
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tMODEL\tCONTEXT\tMAX OUTPUT\tTOOLS\tVISION\tREASONING")
+	for _, m := range models {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%s\t%s\n", m.Provider, m.Name, m.ContextWindow, m.MaxOutputTokens,
+			yesNo(m.SupportsTools), yesNo(m.SupportsVision), yesNo(m.Reasoning))
+	}
+	if err := w.Flush(); err != nil {
+		return fail(stderr, err)
+	}
+	return exitOK
+}
+
+// liveConfig builds a config carrying only the provider's credentials, which
+// is all ListModels needs.
+func liveConfig(provider, endpoint string) *forza.LLMConfig {
+	c := forza.NewLLMConfig().WithProvider(provider)
+	key := os.Getenv(providerProfile(provider, "", endpoint).APIKeyEnv)
+	switch provider {
+	case forza.ProviderAzure:
+		c.WithAzureOpenAiCredentials(key, endpoint)
+	case forza.ProviderAnthropic:
+		c.WithAnthropicCredentials(key)
+	case forza.ProviderGemini:
+		c.WithGeminiCredentials(key)
+	case forza.ProviderOllama:
+		c.WithOllamaCredentials(endpoint)
+	default:
+		c.WithOpenAiCredentials(key)
+	}
+	return c
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
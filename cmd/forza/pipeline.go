@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/vitoraguila/forza"
+)
+
+// pipelineResult is the -json output of run: one result per task, or for a
+// chain only the last task and its final result.
+type pipelineResult struct {
+	Tasks   []string `json:"tasks"`
+	Results []string `json:"results"`
+	CostUSD float64  `json:"costUSD"`
+}
+
+// runPipeline builds and runs a declarative pipeline file with the built-in
+// tools available to its agents. Piped stdin is the pipeline's input: the
+// context of a chain's first task, or of every task otherwise.
+func runPipeline(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("forza run", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	timeout := fs.Duration("timeout", 10*time.Minute, "timeout for the whole pipeline")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if err := fs.Parse(args); err != nil {
+		return parseExit(err)
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(stderr, "Usage: forza run [flags] FILE")
+		return exitUsage
+	}
+
+	def, err := forza.LoadDefinition(fs.Arg(0))
+	if err != nil {
+		return fail(stderr, err)
+	}
+	tools, err := builtinTools()
+	if err != nil {
+		return fail(stderr, err)
+	}
+	pipeline, err := def.Build(tools...)
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	var input []string
+	if isPiped(stdin) {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return fail(stderr, err)
+		}
+		if piped := strings.TrimSpace(string(data)); piped != "" {
+			input = append(input, piped)
+		}
+	}
+
+	tracker := forza.NewCostTracker()
+	ctx, cancel := context.WithTimeout(forza.ContextWithCostTracker(context.Background(), tracker), *timeout)
+	defer cancel()
+
+	results, err := pipeline.Run(ctx, input...)
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	if *asJSON {
+		tasks := pipeline.TaskNames()
+		if pipeline.Mode() == forza.PipelineModeChain {
+			tasks = tasks[len(tasks)-1:]
+		}
+		err = writeJSON(stdout, pipelineResult{Tasks: tasks, Results: results, CostUSD: tracker.Cost()})
+	} else {
+		for i, r := range results {
+			if i > 0 {
+				fmt.Fprintln(stdout)
+			}
+			_, err = fmt.Fprintln(stdout, r)
+		}
+	}
+	if err != nil {
+		return fail(stderr, err)
+	}
+	return exitOK
+}
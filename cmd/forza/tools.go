@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/vitoraguila/forza/tools"
+	"github.com/vitoraguila/forza/tools/scraper"
+)
+
+// builtinTools returns the tools available to the CLI.
+func builtinTools() ([]tools.Tool, error) {
+	s, err := scraper.NewScraper()
+	if err != nil {
+		return nil, err
+	}
+	return []tools.Tool{s}, nil
+}
+
+type toolInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type toolResult struct {
+	Tool   string `json:"tool"`
+	Output string `json:"output"`
+}
+
+// runTools lists the built-in tools, or calls the named one with the rest of
+// the arguments (or piped stdin) as input.
+func runTools(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("forza tools", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	timeout := fs.Duration("timeout", 2*time.Minute, "timeout for the tool call")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if err := fs.Parse(args); err != nil {
+		return parseExit(err)
+	}
+
+	available, err := builtinTools()
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	if fs.NArg() == 0 {
+		infos := make([]toolInfo, 0, len(available))
+		for _, t := range available {
+			infos = append(infos, toolInfo{Name: t.Name(), Description: strings.Join(strings.Fields(t.Description()), " ")})
+		}
+		if *asJSON {
+			err = writeJSON(stdout, infos)
+		} else {
+			for _, info := range infos {
+				if _, err = fmt.Fprintf(stdout, "%s\t%s\n", info.Name, info.Description); err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			return fail(stderr, err)
+		}
+		return exitOK
+	}
+
+	var tool tools.Tool
+	for _, t := range available {
+		if t.Name() == fs.Arg(0) {
+			tool = t
+		}
+	}
+	if tool == nil {
+		fmt.Fprintf(stderr, "forza tools: unknown tool %q\n", fs.Arg(0))
+		return exitUsage
+	}
+
+	input := strings.Join(fs.Args()[1:], " ")
+	if input == "" && isPiped(stdin) {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return fail(stderr, err)
+		}
+		input = strings.TrimSpace(string(data))
+	}
+	if input == "" {
+		fmt.Fprintf(stderr, "forza tools: no input given for %s\n", tool.Name())
+		return exitUsage
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	output, err := tool.Call(ctx, input)
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	if *asJSON {
+		err = writeJSON(stdout, toolResult{Tool: tool.Name(), Output: output})
+	} else {
+		_, err = fmt.Fprintln(stdout, output)
+	}
+	if err != nil {
+		return fail(stderr, err)
+	}
+	return exitOK
+}
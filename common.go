@@ -1,6 +1,7 @@
 package forza
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -164,10 +165,10 @@ var availableModels = map[string]Models{
 	ProviderOllama:    OllamaModels,
 }
 
-// checkModel validates that the given model exists for the provider.
+// checkModel validates that the given model exists for the provider, either
+// as a predefined model or one added with RegisterModel.
 func checkModel(provider, modelName string) (bool, string) {
-	models, exists := availableModels[provider]
-	if !exists {
+	if _, exists := availableModels[provider]; !exists {
 		return false, fmt.Sprintf("provider %q is not registered", provider)
 	}
 
@@ -176,12 +177,28 @@ func checkModel(provider, modelName string) (bool, string) {
 		return true, "model accepted"
 	}
 
-	for _, model := range models.ListModels() {
-		if model == modelName {
-			return true, "model exists"
-		}
+	if _, ok := LookupModel(provider, modelName); ok {
+		return true, "model exists"
+	}
+
+	var names []string
+	for _, info := range CatalogModels(provider) {
+		names = append(names, info.Name)
+	}
+	return false, fmt.Sprintf("model %q does not exist. Available models for %s: %v", modelName, provider, names)
+}
+
+// toolFunc executes a tool call. Providers pass the context of the
+// Completion that requested it, so cancellation, cost tracking and other
+// context values reach the tool.
+type toolFunc func(ctx context.Context, input string) (string, error)
+
+// customToolFunc adapts a function registered with AddCustomTools, which
+// doesn't take a context.
+func customToolFunc(fn func(param string) (string, error)) toolFunc {
+	return func(_ context.Context, input string) (string, error) {
+		return fn(input)
 	}
-	return false, fmt.Sprintf("model %q does not exist. Available models for %s: %v", modelName, provider, models.ListModels())
 }
 
 // buildSystemPrompts creates the standard system prompt slice from an Agent.
@@ -209,7 +226,7 @@ func resolveUserPrompt(userPrompt *string, params []string) (string, error) {
 		return "", ErrTooManyArgs
 	}
 	if len(params) == 1 {
-		prompt = prompt + "\n\nTake in consideration the following context: " + params[0]
+		prompt = prompt + contextSeparator + params[0]
 	}
 	return prompt, nil
 }
@@ -0,0 +1,215 @@
+package forza
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultEnvPrefix is the prefix LoadConfigFromEnv uses when none is given.
+const DefaultEnvPrefix = "FORZA"
+
+// LoadConfigFromEnv builds an LLMConfig from environment variables named
+// <prefix>_PROVIDER, _MODEL, _TEMPERATURE, _MAX_TOKENS, _TIMEOUT (a Go
+// duration such as "30s"), _MAX_RETRIES, _API_KEY and _ENDPOINT. Unset
+// variables keep the NewLLMConfig defaults. An empty prefix means
+// DefaultEnvPrefix. The result is checked with Validate.
+func LoadConfigFromEnv(prefix string) (*LLMConfig, error) {
+	if prefix == "" {
+		prefix = DefaultEnvPrefix
+	}
+	env := func(name string) string {
+		return os.Getenv(prefix + "_" + name)
+	}
+
+	p := Profile{
+		Provider: env("PROVIDER"),
+		Model:    env("MODEL"),
+		Endpoint: env("ENDPOINT"),
+	}
+	if v := env("TEMPERATURE"); v != "" {
+		t, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s_TEMPERATURE: %v", ErrInvalidConfig, prefix, err)
+		}
+		p.Temperature = &t
+	}
+	if v := env("MAX_TOKENS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s_MAX_TOKENS: %v", ErrInvalidConfig, prefix, err)
+		}
+		p.MaxTokens = n
+	}
+	if v := env("TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s_TIMEOUT: %v", ErrInvalidConfig, prefix, err)
+		}
+		p.Timeout = d
+	}
+	if v := env("MAX_RETRIES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s_MAX_RETRIES: %v", ErrInvalidConfig, prefix, err)
+		}
+		p.Retries = &n
+	}
+
+	return p.config(env("API_KEY"))
+}
+
+// Profile describes one named model configuration in a profiles file.
+// Secrets are never stored in the file: APIKeyEnv and EndpointEnv name the
+// environment variables that hold them.
+type Profile struct {
+	Provider    string        `yaml:"provider"`
+	Model       string        `yaml:"model"`
+	Temperature *float64      `yaml:"temperature"`
+	MaxTokens   int           `yaml:"maxTokens"`
+	Timeout     time.Duration `yaml:"timeout"`
+	Retries     *int          `yaml:"retries"`
+	APIKeyEnv   string        `yaml:"apiKeyEnv"`
+	Endpoint    string        `yaml:"endpoint"`
+	EndpointEnv string        `yaml:"endpointEnv"`
+}
+
+// Config resolves the profile's environment references and returns a
+// validated LLMConfig.
+func (p Profile) Config() (*LLMConfig, error) {
+	var apiKey string
+	if p.APIKeyEnv != "" {
+		apiKey = os.Getenv(p.APIKeyEnv)
+		if apiKey == "" {
+			return nil, fmt.Errorf("%w: environment variable %s is not set", ErrMissingAPIKey, p.APIKeyEnv)
+		}
+	}
+	if p.EndpointEnv != "" {
+		p.Endpoint = os.Getenv(p.EndpointEnv)
+		if p.Endpoint == "" {
+			return nil, fmt.Errorf("%w: environment variable %s is not set", ErrMissingEndpoint, p.EndpointEnv)
+		}
+	}
+	return p.config(apiKey)
+}
+
+// config applies the profile on top of the NewLLMConfig defaults.
+func (p Profile) config(apiKey string) (*LLMConfig, error) {
+	c := NewLLMConfig().
+		WithProvider(p.Provider).
+		WithModel(p.Model)
+	c.credentials = credentials{apiKey: apiKey, endpoint: p.Endpoint}
+	if p.Temperature != nil {
+		c.WithTemperature(*p.Temperature)
+	}
+	if p.MaxTokens != 0 {
+		c.WithMaxTokens(p.MaxTokens)
+	}
+	if p.Timeout != 0 {
+		c.WithTimeout(p.Timeout)
+	}
+	if p.Retries != nil {
+		c.WithMaxRetries(*p.Retries)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Profiles is the content of a profiles file:
+//
+//	default: fast
+//	profiles:
+//	  fast:
+//	    provider: openai
+//	    model: gpt-4o-mini
+//	    temperature: 0.2
+//	    timeout: 30s
+//	    apiKeyEnv: OPENAI_API_KEY
+//	  local:
+//	    provider: ollama
+//	    model: llama3.1
+//	    endpoint: http://localhost:11434/v1
+//
+// JSON files use the same keys.
+type Profiles struct {
+	Default  string             `yaml:"default"`
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+// ParseProfiles decodes a YAML or JSON profiles document. Unknown keys are
+// rejected so that typos don't silently fall back to defaults.
+func ParseProfiles(data []byte) (*Profiles, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	var p Profiles
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	if len(p.Profiles) == 0 {
+		return nil, fmt.Errorf("%w: no profiles defined", ErrInvalidConfig)
+	}
+	if p.Default != "" {
+		if _, ok := p.Profiles[p.Default]; !ok {
+			return nil, fmt.Errorf("%w: default profile %q is not defined", ErrProfileNotFound, p.Default)
+		}
+	}
+	return &p, nil
+}
+
+// LoadProfiles reads and parses a YAML or JSON profiles file.
+func LoadProfiles(path string) (*Profiles, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	return ParseProfiles(data)
+}
+
+// Config returns the validated LLMConfig of the named profile. An empty name
+// selects the default profile, or the only profile if there is just one.
+func (p *Profiles) Config(name string) (*LLMConfig, error) {
+	if name == "" {
+		name = p.Default
+	}
+	if name == "" && len(p.Profiles) == 1 {
+		for only := range p.Profiles {
+			name = only
+		}
+	}
+	profile, ok := p.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (available: %v)", ErrProfileNotFound, name, p.Names())
+	}
+	c, err := profile.Config()
+	if err != nil {
+		return nil, fmt.Errorf("profile %q: %w", name, err)
+	}
+	return c, nil
+}
+
+// Names returns the profile names in sorted order.
+func (p *Profiles) Names() []string {
+	names := make([]string, 0, len(p.Profiles))
+	for name := range p.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadProfile is a shorthand for LoadProfiles followed by Profiles.Config.
+func LoadProfile(path, name string) (*LLMConfig, error) {
+	p, err := LoadProfiles(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.Config(name)
+}
@@ -0,0 +1,159 @@
+package forza
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigFromEnv(t *testing.T) {
+	t.Setenv("FORZA_PROVIDER", ProviderAnthropic)
+	t.Setenv("FORZA_MODEL", AnthropicModels.Claude4Sonnet)
+	t.Setenv("FORZA_TEMPERATURE", "0")
+	t.Setenv("FORZA_MAX_TOKENS", "1024")
+	t.Setenv("FORZA_TIMEOUT", "45s")
+	t.Setenv("FORZA_MAX_RETRIES", "5")
+	t.Setenv("FORZA_API_KEY", "secret")
+
+	c, err := LoadConfigFromEnv("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.provider != ProviderAnthropic || c.model != AnthropicModels.Claude4Sonnet {
+		t.Errorf("unexpected provider/model: %s/%s", c.provider, c.model)
+	}
+	if c.temperature != 0 {
+		t.Errorf("expected an explicit temperature of 0, got %f", c.temperature)
+	}
+	if c.maxTokens != 1024 || c.timeout != 45*time.Second || c.maxRetries != 5 {
+		t.Errorf("unexpected limits: %d tokens, %v timeout, %d retries", c.maxTokens, c.timeout, c.maxRetries)
+	}
+	if c.credentials.apiKey != "secret" {
+		t.Errorf("expected API key from FORZA_API_KEY, got %q", c.credentials.apiKey)
+	}
+}
+
+func TestLoadConfigFromEnv_CustomPrefixAndDefaults(t *testing.T) {
+	t.Setenv("MYAPP_PROVIDER", ProviderOllama)
+	t.Setenv("MYAPP_MODEL", "qwen2.5")
+
+	c, err := LoadConfigFromEnv("MYAPP")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defaults := NewLLMConfig()
+	if c.temperature != defaults.temperature || c.maxTokens != defaults.maxTokens || c.timeout != defaults.timeout {
+		t.Errorf("expected defaults for unset variables, got %+v", c)
+	}
+}
+
+func TestLoadConfigFromEnv_Invalid(t *testing.T) {
+	t.Setenv("FORZA_PROVIDER", ProviderOpenAi)
+	t.Setenv("FORZA_MODEL", OpenAIModels.GPT4oMini)
+
+	t.Setenv("FORZA_TIMEOUT", "soon")
+	if _, err := LoadConfigFromEnv(""); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for a bad duration, got %v", err)
+	}
+
+	t.Setenv("FORZA_TIMEOUT", "")
+	t.Setenv("FORZA_TEMPERATURE", "3")
+	if _, err := LoadConfigFromEnv(""); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected Validate to reject temperature 3, got %v", err)
+	}
+}
+
+const testProfilesYAML = `
+default: fast
+profiles:
+  fast:
+    provider: openai
+    model: gpt-4o-mini
+    temperature: 0.2
+    maxTokens: 512
+    timeout: 30s
+    retries: 0
+    apiKeyEnv: TEST_FORZA_OPENAI_KEY
+  local:
+    provider: ollama
+    model: llama3.1
+    endpoint: http://gpu-box:11434/v1
+`
+
+func TestParseProfiles_YAML(t *testing.T) {
+	t.Setenv("TEST_FORZA_OPENAI_KEY", "sk-test")
+
+	p, err := ParseProfiles([]byte(testProfilesYAML))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"fast", "local"}; !reflect.DeepEqual(p.Names(), want) {
+		t.Errorf("expected %v, got %v", want, p.Names())
+	}
+
+	c, err := p.Config("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.model != OpenAIModels.GPT4oMini || c.temperature != 0.2 || c.maxTokens != 512 || c.timeout != 30*time.Second {
+		t.Errorf("unexpected default profile config: %+v", c)
+	}
+	if c.maxRetries != 0 {
+		t.Errorf("expected retries: 0 to be honored, got %d", c.maxRetries)
+	}
+	if c.credentials.apiKey != "sk-test" {
+		t.Errorf("expected API key resolved from the environment, got %q", c.credentials.apiKey)
+	}
+
+	local, err := p.Config("local")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if local.credentials.endpoint != "http://gpu-box:11434/v1" {
+		t.Errorf("unexpected endpoint %q", local.credentials.endpoint)
+	}
+}
+
+func TestLoadProfile_JSON(t *testing.T) {
+	t.Setenv("TEST_FORZA_GEMINI_KEY", "g-key")
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	data := `{"profiles": {"gemini": {"provider": "gemini", "model": "gemini-2.5-flash", "timeout": "1m", "apiKeyEnv": "TEST_FORZA_GEMINI_KEY"}}}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := LoadProfile(path, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.provider != ProviderGemini || c.timeout != time.Minute || c.credentials.apiKey != "g-key" {
+		t.Errorf("unexpected config: %+v", c)
+	}
+}
+
+func TestProfiles_Errors(t *testing.T) {
+	if _, err := ParseProfiles([]byte("profiles:\n  x:\n    provider: openai\n    modle: gpt-4o\n")); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected unknown keys to be rejected, got %v", err)
+	}
+	if _, err := ParseProfiles([]byte("default: missing\nprofiles:\n  x:\n    provider: openai\n")); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("expected ErrProfileNotFound for a bad default, got %v", err)
+	}
+
+	p, err := ParseProfiles([]byte(testProfilesYAML))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := p.Config("nope"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("expected ErrProfileNotFound, got %v", err)
+	}
+	os.Unsetenv("TEST_FORZA_OPENAI_KEY")
+	if _, err := p.Config("fast"); !errors.Is(err, ErrMissingAPIKey) {
+		t.Errorf("expected ErrMissingAPIKey for an unset apiKeyEnv, got %v", err)
+	}
+	if _, err := LoadProfiles(filepath.Join(t.TempDir(), "missing.yaml")); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for a missing file, got %v", err)
+	}
+}
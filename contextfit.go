@@ -0,0 +1,80 @@
+package forza
+
+import (
+	"fmt"
+
+	"github.com/vitoraguila/forza/tokenizer"
+)
+
+// ContextFit selects what happens when the user prompt plus its context would
+// overflow the model's context window.
+type ContextFit int
+
+const (
+	// ContextFitNone sends the prompt as-is and lets the provider reject it.
+	ContextFitNone ContextFit = iota
+	// ContextFitError fails with ErrContextOverflow before calling the API.
+	ContextFitError
+	// ContextFitTruncate keeps the beginning of the context and cuts the rest.
+	ContextFitTruncate
+	// ContextFitDropOldest drops the beginning of the context and keeps the
+	// most recent part.
+	ContextFitDropOldest
+)
+
+// contextSeparator is what resolveUserPrompt places between the prompt and
+// its context.
+const contextSeparator = "\n\nTake in consideration the following context: "
+
+// messageOverhead approximates the per-message framing tokens added by chat APIs.
+const messageOverhead = 4
+
+// CountTokens counts the tokens in text using the model's tokenizer.
+func CountTokens(model, text string) int {
+	return tokenizer.ForModel(model).Count(text)
+}
+
+// fitUserPrompt resolves the user prompt like resolveUserPrompt and then
+// applies the config's ContextFit strategy so that the system prompts, the
+// user prompt, its context and the reserved output tokens fit the model's
+// context window.
+func fitUserPrompt(c *LLMConfig, systemPrompts []agentPrompts, userPrompt *string, params []string) (string, error) {
+	prompt, err := resolveUserPrompt(userPrompt, params)
+	if err != nil || c.contextFit == ContextFitNone {
+		return prompt, err
+	}
+	window := c.modelInfo().ContextWindow
+	if window == 0 {
+		return prompt, nil
+	}
+
+	tok := tokenizer.ForModel(c.model)
+	fixed := c.outputTokens() + tok.Count(*userPrompt) + messageOverhead
+	for _, p := range systemPrompts {
+		fixed += tok.Count(p.Context) + messageOverhead
+	}
+
+	var context string
+	if len(params) == 1 {
+		context = params[0]
+	}
+	contextTokens := 0
+	if context != "" {
+		contextTokens = tok.Count(contextSeparator) + tok.Count(context)
+	}
+	if fixed+contextTokens <= window {
+		return prompt, nil
+	}
+
+	available := window - fixed - tok.Count(contextSeparator)
+	if c.contextFit == ContextFitError || context == "" || available <= 0 {
+		return "", fmt.Errorf("%w: %d tokens needed, %s allows %d", ErrContextOverflow, fixed+contextTokens, c.model, window)
+	}
+
+	if c.contextFit == ContextFitDropOldest {
+		context = tok.Tail(context, available)
+	} else {
+		context = tok.Head(context, available)
+	}
+	return resolveUserPrompt(userPrompt, []string{context})
+}
@@ -0,0 +1,155 @@
+package forza
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newFitConfig(strategy ContextFit) *LLMConfig {
+	return NewLLMConfig().
+		WithProvider(ProviderOllama).
+		WithModel(OllamaModels.Llama3). // 8192-token window
+		WithMaxTokens(1000).
+		WithContextFit(strategy)
+}
+
+var fitSystemPrompts = buildSystemPrompts(&Agent{Role: "Tester", Backstory: "backstory", Goal: "goal"})
+
+func TestContextWindow(t *testing.T) {
+	if got := ContextWindow(AnthropicModels.Claude4Sonnet); got != 200000 {
+		t.Errorf("expected 200000, got %d", got)
+	}
+	if got := ContextWindow("unknown-model"); got != 0 {
+		t.Errorf("expected 0 for unknown model, got %d", got)
+	}
+}
+
+func TestCountTokens(t *testing.T) {
+	if got := CountTokens(OpenAIModels.GPT4o, "hello world"); got != 2 {
+		t.Errorf("expected 2 tokens, got %d", got)
+	}
+}
+
+func TestContextWindow_CoversPredefinedModels(t *testing.T) {
+	for _, list := range []Models{OpenAIModels, AnthropicModels, GeminiModels, OllamaModels} {
+		for _, model := range list.ListModels() {
+			if ContextWindow(model) == 0 {
+				t.Errorf("missing context window for %q", model)
+			}
+		}
+	}
+}
+
+func TestFitUserPrompt_FitsUnchanged(t *testing.T) {
+	prompt := "summarize"
+	got, err := fitUserPrompt(newFitConfig(ContextFitTruncate), fitSystemPrompts, &prompt, []string{"short context"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "summarize"+contextSeparator+"short context" {
+		t.Errorf("expected prompt unchanged, got %q", got)
+	}
+}
+
+func TestFitUserPrompt_None(t *testing.T) {
+	prompt := "summarize"
+	huge := strings.Repeat("x", 100000)
+	got, err := fitUserPrompt(newFitConfig(ContextFitNone), fitSystemPrompts, &prompt, []string{huge})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasSuffix(got, huge) {
+		t.Error("expected context to be sent as-is with ContextFitNone")
+	}
+}
+
+func TestFitUserPrompt_Error(t *testing.T) {
+	prompt := "summarize"
+	huge := strings.Repeat("x", 100000)
+	_, err := fitUserPrompt(newFitConfig(ContextFitError), fitSystemPrompts, &prompt, []string{huge})
+	if !errors.Is(err, ErrContextOverflow) {
+		t.Errorf("expected ErrContextOverflow, got %v", err)
+	}
+}
+
+func TestFitUserPrompt_Truncate(t *testing.T) {
+	prompt := "summarize"
+	context := "BEGIN" + strings.Repeat("x", 100000) + "END"
+	cfg := newFitConfig(ContextFitTruncate)
+
+	got, err := fitUserPrompt(cfg, fitSystemPrompts, &prompt, []string{context})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(got, "summarize"+contextSeparator+"BEGIN") {
+		t.Error("expected the beginning of the context to be kept")
+	}
+	if strings.HasSuffix(got, "END") {
+		t.Error("expected the end of the context to be cut")
+	}
+	if n := CountTokens(cfg.model, got) + cfg.maxTokens; n > ContextWindow(cfg.model) {
+		t.Errorf("expected fitted prompt within the window, got %d tokens", n)
+	}
+}
+
+func TestFitUserPrompt_DropOldest(t *testing.T) {
+	prompt := "summarize"
+	context := "BEGIN" + strings.Repeat("x", 100000) + "END"
+
+	got, err := fitUserPrompt(newFitConfig(ContextFitDropOldest), fitSystemPrompts, &prompt, []string{context})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(got, "BEGIN") {
+		t.Error("expected the oldest part of the context to be dropped")
+	}
+	if !strings.HasSuffix(got, "END") {
+		t.Error("expected the most recent part of the context to be kept")
+	}
+}
+
+func TestFitUserPrompt_PromptAloneTooLarge(t *testing.T) {
+	prompt := strings.Repeat("x", 100000)
+	_, err := fitUserPrompt(newFitConfig(ContextFitTruncate), fitSystemPrompts, &prompt, []string{"ctx"})
+	if !errors.Is(err, ErrContextOverflow) {
+		t.Errorf("expected ErrContextOverflow when the prompt itself overflows, got %v", err)
+	}
+}
+
+func TestFitUserPrompt_UnknownModel(t *testing.T) {
+	prompt := "summarize"
+	cfg := newFitConfig(ContextFitError).WithModel("my-custom-model")
+	if _, err := fitUserPrompt(cfg, fitSystemPrompts, &prompt, []string{strings.Repeat("x", 100000)}); err != nil {
+		t.Errorf("expected no fitting for a model with unknown window, got %v", err)
+	}
+}
+
+func TestFitUserPrompt_MissingPrompt(t *testing.T) {
+	if _, err := fitUserPrompt(newFitConfig(ContextFitTruncate), fitSystemPrompts, nil, nil); !errors.Is(err, ErrMissingPrompt) {
+		t.Errorf("expected ErrMissingPrompt, got %v", err)
+	}
+}
+
+func TestAnthropic_Completion_ContextOverflowSkipsRequest(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	task := newTestAnthropicTask(server.URL)
+	task.(*anthropicProvider).config.WithContextFit(ContextFitError)
+	task.WithUserPrompt("hello")
+
+	_, err := task.Completion(context.Background(), strings.Repeat("lorem ipsum ", 100000))
+	if !errors.Is(err, ErrContextOverflow) {
+		t.Errorf("expected ErrContextOverflow, got %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("expected no API request, got %d", requests)
+	}
+}
@@ -0,0 +1,270 @@
+package forza
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/vitoraguila/forza/tokenizer"
+)
+
+// Message roles in a conversation history.
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool"
+	// RoleSummary marks a message that summarizes earlier turns.
+	RoleSummary = "summary"
+)
+
+const (
+	defaultSummaryThreshold = 3000
+	defaultKeepTurns        = 4
+
+	summarizerPrompt = `Summarize the conversation given as context so that it can be continued without it. ` +
+		`It may start with a summary of even earlier turns; fold that in. Keep names, facts, decisions, open questions ` +
+		`and anything the user asked to remember. Answer with the summary only.`
+)
+
+// Message is one entry of a conversation history. An assistant message that
+// calls tools lists the call IDs in ToolCalls, and each tool message names
+// the call it answers in ToolCallID.
+type Message struct {
+	Role       string   `json:"role"`
+	Content    string   `json:"content"`
+	ToolCalls  []string `json:"tool_calls,omitempty"`
+	ToolCallID string   `json:"tool_call_id,omitempty"`
+}
+
+// HistoryStrategy keeps a conversation history within bounds. Fit returns
+// the history to keep; it may drop or replace older turns but must keep the
+// latest message.
+type HistoryStrategy interface {
+	Fit(ctx context.Context, history []Message) ([]Message, error)
+}
+
+// historyTurns splits history into turns, each starting at a user message.
+// Tool calls and their results always fall in the same turn, so cutting
+// between turns never separates them. Messages before the first user
+// message, such as a summary, form their own turn.
+func historyTurns(history []Message) [][]Message {
+	var turns [][]Message
+	start := 0
+	for i, m := range history {
+		if m.Role == RoleUser && i > start {
+			turns = append(turns, history[start:i])
+			start = i
+		}
+	}
+	if start < len(history) {
+		turns = append(turns, history[start:])
+	}
+	return turns
+}
+
+// renderHistory writes messages as "role: content" lines. Tool calls and
+// results carry their call IDs, e.g. "assistant: [calls call_1, call_2]" and
+// "tool (call_1): sunny", so each result can be matched to its call.
+func renderHistory(messages []Message) string {
+	lines := make([]string, 0, len(messages))
+	for _, m := range messages {
+		switch {
+		case m.Role == RoleSummary:
+			lines = append(lines, "summary of earlier conversation: "+m.Content)
+		case len(m.ToolCalls) > 0:
+			line := m.Role + ": [calls " + strings.Join(m.ToolCalls, ", ") + "]"
+			if m.Content != "" {
+				line += " " + m.Content
+			}
+			lines = append(lines, line)
+		case m.ToolCallID != "":
+			lines = append(lines, m.Role+" ("+m.ToolCallID+"): "+m.Content)
+		default:
+			lines = append(lines, m.Role+": "+m.Content)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SlidingWindow keeps the last turns of a conversation and drops the rest.
+type SlidingWindow struct {
+	turns int
+}
+
+// NewSlidingWindow creates a SlidingWindow that keeps the last turns turns,
+// a turn being a user message and everything up to the next one.
+func NewSlidingWindow(turns int) *SlidingWindow {
+	return &SlidingWindow{turns: turns}
+}
+
+// Fit drops all but the last turns.
+func (s *SlidingWindow) Fit(ctx context.Context, history []Message) ([]Message, error) {
+	turns := historyTurns(history)
+	keep := max(s.turns, 1)
+	if len(turns) <= keep {
+		return history, nil
+	}
+	var kept []Message
+	for _, turn := range turns[len(turns)-keep:] {
+		kept = append(kept, turn...)
+	}
+	return kept, nil
+}
+
+// SummarizingHistory keeps a conversation under a token threshold by
+// replacing its oldest turns with a rolling summary written by an agent.
+// Once the history crosses the threshold, everything but the last turns,
+// including any earlier summary, is summarized into a single RoleSummary
+// message.
+//
+// The summarizer's user prompt is replaced, so it should be dedicated to the
+// strategy. When the context carries a CostTracker, summaries are tracked
+// under a child named "summary".
+type SummarizingHistory struct {
+	summarizer LLMAgent
+	tokenizer  tokenizer.Tokenizer
+	threshold  int
+	keepTurns  int
+}
+
+// NewSummarizingHistory creates a SummarizingHistory that summarizes with
+// summarizer and counts tokens with the tokenizer of model, which should be
+// the conversation's model.
+func NewSummarizingHistory(summarizer LLMAgent, model string) *SummarizingHistory {
+	if summarizer != nil {
+		summarizer.WithUserPrompt(summarizerPrompt)
+	}
+	return &SummarizingHistory{
+		summarizer: summarizer,
+		tokenizer:  tokenizer.ForModel(model),
+		threshold:  defaultSummaryThreshold,
+		keepTurns:  defaultKeepTurns,
+	}
+}
+
+// WithThreshold sets the history size, in tokens, above which older turns
+// are summarized. Default is 3000.
+func (s *SummarizingHistory) WithThreshold(tokens int) *SummarizingHistory {
+	s.threshold = tokens
+	return s
+}
+
+// WithKeepTurns sets how many of the latest turns are kept verbatim.
+// Default is 4.
+func (s *SummarizingHistory) WithKeepTurns(n int) *SummarizingHistory {
+	s.keepTurns = n
+	return s
+}
+
+// Fit summarizes the older turns once history exceeds the threshold. A
+// history under the threshold, or with no turns beyond those kept, is
+// returned unchanged.
+func (s *SummarizingHistory) Fit(ctx context.Context, history []Message) ([]Message, error) {
+	if s.summarizer == nil {
+		return nil, fmt.Errorf("%w: summarizing history has no summarizer", ErrNilTask)
+	}
+	if s.tokenizer.Count(renderHistory(history)) <= s.threshold {
+		return history, nil
+	}
+	turns := historyTurns(history)
+	keep := max(s.keepTurns, 1)
+	if len(turns) <= keep {
+		return history, nil
+	}
+
+	var older, kept []Message
+	for _, turn := range turns[:len(turns)-keep] {
+		older = append(older, turn...)
+	}
+	for _, turn := range turns[len(turns)-keep:] {
+		kept = append(kept, turn...)
+	}
+	summary, err := s.summarizer.Completion(internalTaskContext(ctx, "summary"), renderHistory(older))
+	if err != nil {
+		return nil, fmt.Errorf("summarizing history: %w", err)
+	}
+	return append([]Message{{Role: RoleSummary, Content: strings.TrimSpace(summary)}}, kept...), nil
+}
+
+// Conversation is a multi-turn exchange with an agent. Each Send passes the
+// new message as the agent's user prompt and the earlier history, rendered
+// as "role: content" lines, as its context. A HistoryStrategy keeps the
+// history within bounds; without one, it grows without limit.
+//
+// Send sets the agent's user prompt, so the agent should be dedicated to the
+// conversation. Sends are serialized.
+type Conversation struct {
+	mu       sync.Mutex
+	agent    LLMAgent
+	strategy HistoryStrategy
+	history  []Message
+}
+
+// NewConversation starts an empty conversation with agent.
+func NewConversation(agent LLMAgent) *Conversation {
+	return &Conversation{agent: agent}
+}
+
+// WithHistoryStrategy sets how the history is kept within bounds, such as
+// NewSlidingWindow or NewSummarizingHistory.
+func (c *Conversation) WithHistoryStrategy(s HistoryStrategy) *Conversation {
+	c.strategy = s
+	return c
+}
+
+// AddMessages appends messages to the history, for example to restore a
+// saved conversation or record tool calls made elsewhere.
+func (c *Conversation) AddMessages(messages ...Message) *Conversation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.history = append(c.history, messages...)
+	return c
+}
+
+// History returns a copy of the current history, as fitted by the strategy
+// for the last answered message.
+func (c *Conversation) History() []Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Message(nil), c.history...)
+}
+
+// Send adds message to the history, fits the history, and returns the
+// agent's answer. Only a successful answer replaces the history with the
+// fitted one plus the answer; if fitting or the completion fails, the message
+// stays in the unfitted history without an answer.
+func (c *Conversation) Send(ctx context.Context, message string) (string, error) {
+	if c.agent == nil {
+		return "", fmt.Errorf("%w: conversation has no agent", ErrNilTask)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.history = append(c.history, Message{Role: RoleUser, Content: message})
+	history := c.history
+	if c.strategy != nil {
+		fitted, err := c.strategy.Fit(ctx, history)
+		if err != nil {
+			return "", err
+		}
+		if n := len(fitted); n == 0 || fitted[n-1].Role != RoleUser || fitted[n-1].Content != message {
+			return "", fmt.Errorf("%w: history strategy must keep the new message last", ErrInvalidConfig)
+		}
+		history = fitted
+	}
+
+	c.agent.WithUserPrompt(message)
+	var answer string
+	var err error
+	if earlier := history[:len(history)-1]; len(earlier) > 0 {
+		answer, err = c.agent.Completion(ctx, renderHistory(earlier))
+	} else {
+		answer, err = c.agent.Completion(ctx)
+	}
+	if err != nil {
+		return "", err
+	}
+	c.history = append(history[:len(history):len(history)], Message{Role: RoleAssistant, Content: answer})
+	return answer, nil
+}
@@ -0,0 +1,162 @@
+package forza
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// echoAgent answers with the prompt it was given and records its contexts.
+func echoAgent() (*stubAgent, *[]string) {
+	var contexts []string
+	a := &stubAgent{}
+	a.complete = func(ctx context.Context, params ...string) (string, error) {
+		contexts = append(contexts, strings.Join(params, ""))
+		return "re: " + a.prompt, nil
+	}
+	return a, &contexts
+}
+
+func roles(history []Message) string {
+	out := make([]string, len(history))
+	for i, m := range history {
+		out[i] = m.Role
+	}
+	return strings.Join(out, ",")
+}
+
+func TestConversation_Send(t *testing.T) {
+	agent, contexts := echoAgent()
+	c := NewConversation(agent)
+
+	if answer, err := c.Send(context.Background(), "hi"); err != nil || answer != "re: hi" {
+		t.Fatalf("unexpected answer %q, %v", answer, err)
+	}
+	c.Send(context.Background(), "how are you?")
+	if (*contexts)[0] != "" || (*contexts)[1] != "user: hi\nassistant: re: hi" {
+		t.Errorf("expected the earlier turns as context, got %q", *contexts)
+	}
+	if got := roles(c.History()); got != "user,assistant,user,assistant" {
+		t.Errorf("unexpected history %s", got)
+	}
+
+	agent.complete = func(context.Context, ...string) (string, error) { return "", errors.New("down") }
+	if _, err := c.Send(context.Background(), "still there?"); err == nil {
+		t.Fatal("expected the completion error")
+	}
+	if got := roles(c.History()); got != "user,assistant,user,assistant,user" {
+		t.Errorf("expected the unanswered message to stay in the history, got %s", got)
+	}
+}
+
+func TestSlidingWindow_KeepsToolPairs(t *testing.T) {
+	history := []Message{
+		{Role: RoleUser, Content: "weather in Lisbon and Porto?"},
+		{Role: RoleAssistant, ToolCalls: []string{"call_1", "call_2"}},
+		{Role: RoleTool, ToolCallID: "call_1", Content: "sunny"},
+		{Role: RoleTool, ToolCallID: "call_2", Content: "rain"},
+		{Role: RoleAssistant, Content: "Sunny in Lisbon, rain in Porto"},
+		{Role: RoleUser, Content: "and Faro?"},
+		{Role: RoleAssistant, ToolCalls: []string{"call_3"}},
+		{Role: RoleTool, ToolCallID: "call_3", Content: "windy"},
+	}
+
+	got, err := NewSlidingWindow(1).Fit(context.Background(), history)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if roles(got) != "user,assistant,tool" || got[2].ToolCallID != "call_3" {
+		t.Errorf("expected the last turn with its tool call and result, got %+v", got)
+	}
+	if rendered := renderHistory(history[:5]); rendered != "user: weather in Lisbon and Porto?\n"+
+		"assistant: [calls call_1, call_2]\n"+
+		"tool (call_1): sunny\n"+
+		"tool (call_2): rain\n"+
+		"assistant: Sunny in Lisbon, rain in Porto" {
+		t.Errorf("expected tool calls and results rendered with their call IDs, got %q", rendered)
+	}
+	if got, _ := NewSlidingWindow(5).Fit(context.Background(), history); len(got) != len(history) {
+		t.Errorf("expected a short history to be kept whole, got %d messages", len(got))
+	}
+}
+
+func TestSummarizingHistory(t *testing.T) {
+	var summarized []string
+	summarizer := &stubAgent{}
+	summarizer.complete = func(ctx context.Context, params ...string) (string, error) {
+		summarized = append(summarized, params[0])
+		return fmt.Sprintf(" summary %d ", len(summarized)), nil
+	}
+	strategy := NewSummarizingHistory(summarizer, OpenAIModels.GPT4o).WithThreshold(40).WithKeepTurns(2)
+	if !strings.Contains(summarizer.prompt, "Summarize") {
+		t.Errorf("expected summarizer instructions, got %q", summarizer.prompt)
+	}
+
+	agent, contexts := echoAgent()
+	c := NewConversation(agent).WithHistoryStrategy(strategy)
+	for i := 1; i <= 8; i++ {
+		if _, err := c.Send(context.Background(), fmt.Sprintf("message number %d with some extra words", i)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	history := c.History()
+	if roles(history) != "summary,user,assistant,user,assistant" || history[0].Content != fmt.Sprintf("summary %d", len(summarized)) {
+		t.Fatalf("expected a rolling summary followed by the last two turns, got %+v", history)
+	}
+	if history[1].Content != "message number 7 with some extra words" {
+		t.Errorf("expected the last turns verbatim, got %q", history[1].Content)
+	}
+	if len(summarized) < 2 || !strings.HasPrefix(summarized[1], "summary of earlier conversation: summary 1\n") {
+		t.Errorf("expected the previous summary to be folded into the next, got %q", summarized)
+	}
+	last := (*contexts)[len(*contexts)-1]
+	if !strings.HasPrefix(last, "summary of earlier conversation: ") || strings.Contains(last, "message number 1 ") {
+		t.Errorf("expected the agent to see the summary instead of old turns, got %q", last)
+	}
+
+	summarizer.complete = func(context.Context, ...string) (string, error) { return "", errors.New("down") }
+	long := strings.Repeat("long message ", 20)
+	if _, err := c.Send(context.Background(), long); err == nil || !strings.Contains(err.Error(), "summarizing history") {
+		t.Errorf("expected the summarizer error, got %v", err)
+	}
+	history = c.History()
+	if roles(history) != "summary,user,assistant,user,assistant,user" || history[len(history)-1].Content != long {
+		t.Errorf("expected the unfitted history to keep the unanswered message, got %+v", history)
+	}
+
+	summarizer.complete = func(context.Context, ...string) (string, error) { return "fresh summary", nil }
+	agent.complete = func(context.Context, ...string) (string, error) { return "", errors.New("down") }
+	if _, err := c.Send(context.Background(), "one more"); err == nil {
+		t.Fatal("expected the completion error")
+	}
+	history = c.History()
+	if roles(history) != "summary,user,assistant,user,assistant,user,user" || history[0].Content == "fresh summary" {
+		t.Errorf("expected a failed completion to leave the history unfitted, got %+v", history)
+	}
+}
+
+// fitFunc adapts a function to HistoryStrategy.
+type fitFunc func(ctx context.Context, history []Message) ([]Message, error)
+
+func (f fitFunc) Fit(ctx context.Context, history []Message) ([]Message, error) {
+	return f(ctx, history)
+}
+
+func TestConversation_StrategyMustKeepMessage(t *testing.T) {
+	agent, _ := echoAgent()
+	for _, fit := range []fitFunc{
+		func(context.Context, []Message) ([]Message, error) { return nil, nil },
+		func(_ context.Context, h []Message) ([]Message, error) { return h[:len(h)-1], nil },
+	} {
+		c := NewConversation(agent).AddMessages(Message{Role: RoleUser, Content: "earlier"}).WithHistoryStrategy(fit)
+		if _, err := c.Send(context.Background(), "hi"); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("expected ErrInvalidConfig, got %v", err)
+		}
+		if got := roles(c.History()); got != "user,user" {
+			t.Errorf("expected the message kept in the unfitted history, got %s", got)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package forza
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// CostTracker accumulates token usage and USD cost. Trackers form a tree:
+// usage recorded on a child also rolls up into every ancestor, and a budget
+// set on any ancestor applies to the whole subtree.
+//
+// Attach a tracker to a context with ContextWithCostTracker. Providers record
+// every call against the tracker found in the context, and Pipeline runs give
+// each task its own child tracker named "task N".
+type CostTracker struct {
+	mu       sync.Mutex
+	name     string
+	parent   *CostTracker
+	budget   float64
+	usage    Usage
+	cost     float64
+	calls    int
+	children []*CostTracker
+}
+
+// NewCostTracker creates an empty root tracker with no budget.
+func NewCostTracker() *CostTracker {
+	return &CostTracker{}
+}
+
+// WithBudget sets a USD spend limit. Once the tracker's cost reaches the
+// limit, further calls fail with ErrBudgetExceeded. Zero means no limit.
+func (t *CostTracker) WithBudget(usd float64) *CostTracker {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.budget = usd
+	return t
+}
+
+// Child creates a named child tracker whose usage rolls up into t.
+func (t *CostTracker) Child(name string) *CostTracker {
+	c := &CostTracker{name: name, parent: t}
+	t.mu.Lock()
+	t.children = append(t.children, c)
+	t.mu.Unlock()
+	return c
+}
+
+// Record adds the usage of one call to a model to this tracker and its ancestors.
+func (t *CostTracker) Record(model string, u Usage) {
+	cost := EstimateCost(model, u)
+	for n := t; n != nil; n = n.parent {
+		n.mu.Lock()
+		n.usage = n.usage.Add(u)
+		n.cost += cost
+		n.calls++
+		n.mu.Unlock()
+	}
+}
+
+// Name returns the tracker's name. Root trackers are unnamed.
+func (t *CostTracker) Name() string {
+	return t.name
+}
+
+// Cost returns the accumulated USD cost.
+func (t *CostTracker) Cost() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.cost
+}
+
+// Usage returns the accumulated token usage.
+func (t *CostTracker) Usage() Usage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.usage
+}
+
+// Calls returns the number of recorded calls.
+func (t *CostTracker) Calls() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.calls
+}
+
+// Children returns the child trackers in creation order.
+func (t *CostTracker) Children() []*CostTracker {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*CostTracker(nil), t.children...)
+}
+
+// checkBudget returns ErrBudgetExceeded if t or any ancestor has reached its budget.
+func (t *CostTracker) checkBudget() error {
+	for n := t; n != nil; n = n.parent {
+		n.mu.Lock()
+		budget, cost := n.budget, n.cost
+		n.mu.Unlock()
+		if budget > 0 && cost >= budget {
+			return fmt.Errorf("%w: spent $%.4f of $%.4f", ErrBudgetExceeded, cost, budget)
+		}
+	}
+	return nil
+}
+
+type costTrackerKey struct{}
+
+// ContextWithCostTracker returns a context that carries the given tracker.
+func ContextWithCostTracker(ctx context.Context, t *CostTracker) context.Context {
+	return context.WithValue(ctx, costTrackerKey{}, t)
+}
+
+// CostTrackerFromContext returns the tracker carried by ctx, or nil.
+func CostTrackerFromContext(ctx context.Context) *CostTracker {
+	t, _ := ctx.Value(costTrackerKey{}).(*CostTracker)
+	return t
+}
+
+// checkBudget fails fast when the context's tracker is out of budget.
+func checkBudget(ctx context.Context) error {
+	if t := CostTrackerFromContext(ctx); t != nil {
+		return t.checkBudget()
+	}
+	return nil
+}
+
+// recordUsage records a call against the context's tracker, if any.
+func recordUsage(ctx context.Context, model string, u Usage) {
+	if t := CostTrackerFromContext(ctx); t != nil {
+		t.Record(model, u)
+	}
+}
+
+// taskContext gives a pipeline task its own child tracker so costs can be
+// reported per task.
+func taskContext(ctx context.Context, index int) context.Context {
+	return namedTaskContext(ctx, fmt.Sprintf("task %d", index+1))
+}
+
+type taskNameKey struct{}
+
+// namedTaskContext names a task and gives it a child tracker with the same
+// name.
+func namedTaskContext(ctx context.Context, name string) context.Context {
+	ctx = context.WithValue(ctx, taskNameKey{}, name)
+	if t := CostTrackerFromContext(ctx); t != nil {
+		return ContextWithCostTracker(ctx, t.Child(name))
+	}
+	return ctx
+}
+
+// taskName returns the name of the innermost task running under ctx, or "".
+func taskName(ctx context.Context) string {
+	name, _ := ctx.Value(taskNameKey{}).(string)
+	return name
+}
@@ -0,0 +1,170 @@
+package forza
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestCostTracker_RecordRollsUp(t *testing.T) {
+	root := NewCostTracker()
+	child := root.Child("task 1")
+	grandchild := child.Child("step")
+
+	grandchild.Record(AnthropicModels.Claude4Sonnet, Usage{PromptTokens: 1_000_000})
+
+	for _, tr := range []*CostTracker{root, child, grandchild} {
+		if !almostEqual(tr.Cost(), 3) {
+			t.Errorf("%q: expected $3, got %v", tr.Name(), tr.Cost())
+		}
+		if tr.Calls() != 1 {
+			t.Errorf("%q: expected 1 call, got %d", tr.Name(), tr.Calls())
+		}
+		if tr.Usage().PromptTokens != 1_000_000 {
+			t.Errorf("%q: unexpected usage %+v", tr.Name(), tr.Usage())
+		}
+	}
+	if len(root.Children()) != 1 || root.Children()[0].Name() != "task 1" {
+		t.Errorf("unexpected children: %v", root.Children())
+	}
+}
+
+func TestCostTracker_Budget(t *testing.T) {
+	root := NewCostTracker().WithBudget(1)
+	child := root.Child("task 1")
+
+	if err := child.checkBudget(); err != nil {
+		t.Fatalf("unexpected error before spending: %v", err)
+	}
+	child.Record(OpenAIModels.GPT4o, Usage{PromptTokens: 400_000}) // $1.00
+
+	if err := child.checkBudget(); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("expected ErrBudgetExceeded from an ancestor budget, got %v", err)
+	}
+}
+
+func TestCostTracker_ConcurrentRecord(t *testing.T) {
+	root := NewCostTracker()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			root.Child("c").Record(OpenAIModels.GPT4oMini, Usage{PromptTokens: 10, CompletionTokens: 10})
+		}()
+	}
+	wg.Wait()
+
+	if root.Calls() != 50 {
+		t.Errorf("expected 50 calls, got %d", root.Calls())
+	}
+	if root.Usage().TotalTokens() != 1000 {
+		t.Errorf("expected 1000 tokens, got %d", root.Usage().TotalTokens())
+	}
+}
+
+func TestCostTrackerFromContext(t *testing.T) {
+	if CostTrackerFromContext(context.Background()) != nil {
+		t.Error("expected nil tracker for a bare context")
+	}
+	tr := NewCostTracker()
+	ctx := ContextWithCostTracker(context.Background(), tr)
+	if CostTrackerFromContext(ctx) != tr {
+		t.Error("expected the attached tracker")
+	}
+	if err := checkBudget(context.Background()); err != nil {
+		t.Errorf("expected no budget error without a tracker, got %v", err)
+	}
+}
+
+func TestPipeline_CostPerTask(t *testing.T) {
+	spend := func(tokens int) TaskChainFn {
+		return func(ctx context.Context, params ...string) (string, error) {
+			recordUsage(ctx, OpenAIModels.GPT4o, Usage{PromptTokens: tokens})
+			return "ok", nil
+		}
+	}
+
+	p := NewPipeline()
+	p.AddTasks(spend(100_000), spend(300_000))
+
+	run := NewCostTracker()
+	if _, err := p.RunConcurrently(ContextWithCostTracker(context.Background(), run)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !almostEqual(run.Cost(), 1) {
+		t.Errorf("expected run cost $1, got %v", run.Cost())
+	}
+	costs := map[string]float64{}
+	for _, c := range run.Children() {
+		costs[c.Name()] = c.Cost()
+	}
+	if !almostEqual(costs["task 1"], 0.25) || !almostEqual(costs["task 2"], 0.75) {
+		t.Errorf("unexpected per-task costs: %v", costs)
+	}
+}
+
+func TestPipeline_ChainStopsWhenBudgetExceeded(t *testing.T) {
+	calls := 0
+	spend := func(ctx context.Context, params ...string) (string, error) {
+		if err := checkBudget(ctx); err != nil {
+			return "", err
+		}
+		calls++
+		recordUsage(ctx, OpenAIModels.GPT4o, Usage{PromptTokens: 400_000})
+		return "ok", nil
+	}
+
+	p := NewPipeline()
+	chain := p.CreateChain(spend, spend, spend)
+
+	run := NewCostTracker().WithBudget(1)
+	_, err := chain(ContextWithCostTracker(context.Background(), run))
+	if !errors.Is(err, ErrChainInterrupted) {
+		t.Fatalf("expected ErrChainInterrupted, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected the chain to stop after the budget was spent, got %d calls", calls)
+	}
+}
+
+func TestAnthropic_Completion_RecordsCost(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		resp := anthropicResponse{
+			Type:    "message",
+			Role:    "assistant",
+			Content: []anthropicContentBlock{{Type: "text", Text: "ok"}},
+			Usage:   &anthropicUsage{InputTokens: 1_000_000, OutputTokens: 100_000},
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	task := newTestAnthropicTask(server.URL)
+	task.WithUserPrompt("hello")
+
+	tracker := NewCostTracker().WithBudget(4)
+	ctx := ContextWithCostTracker(context.Background(), tracker)
+
+	if _, err := task.Completion(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !almostEqual(tracker.Cost(), 4.5) {
+		t.Errorf("expected $4.50 for claude sonnet 4, got %v", tracker.Cost())
+	}
+
+	_, err := task.Completion(ctx)
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("expected ErrBudgetExceeded, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("expected no request once over budget, got %d requests", requests)
+	}
+}
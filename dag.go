@@ -0,0 +1,269 @@
+package forza
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NodeStatus is the state of a DAG node.
+type NodeStatus int
+
+const (
+	NodePending NodeStatus = iota
+	NodeRunning
+	NodeSucceeded
+	NodeFailed
+	// NodeSkipped means the node never ran because a dependency did not
+	// succeed or the context was cancelled first.
+	NodeSkipped
+)
+
+func (s NodeStatus) String() string {
+	switch s {
+	case NodePending:
+		return "pending"
+	case NodeRunning:
+		return "running"
+	case NodeSucceeded:
+		return "succeeded"
+	case NodeFailed:
+		return "failed"
+	case NodeSkipped:
+		return "skipped"
+	default:
+		return fmt.Sprintf("NodeStatus(%d)", int(s))
+	}
+}
+
+// NodeResult reports the outcome of one DAG node.
+type NodeResult struct {
+	Status   NodeStatus
+	Output   string
+	Err      error
+	Duration time.Duration
+}
+
+type dagNode struct {
+	name      string
+	task      TaskChainFn
+	dependsOn []string
+}
+
+// DAG runs tasks as a dependency graph: a node starts as soon as all of its
+// dependencies have succeeded, so independent branches run concurrently.
+//
+// A node without dependencies is called with no context. A node with one
+// dependency receives that dependency's output as its context, and a node
+// with several receives their outputs as a single context, each under a
+// "### name" heading in declaration order. This keeps every LLMAgent's
+// Completion usable as a node.
+//
+// When the context carries a CostTracker, each node gets a child tracker
+// named after the node.
+type DAG struct {
+	nodes  []*dagNode
+	logger *slog.Logger
+}
+
+// NewDAG creates an empty DAG.
+func NewDAG() *DAG {
+	return &DAG{}
+}
+
+// WithLogger sets an optional logger for the DAG. If nil, no logging occurs.
+func (d *DAG) WithLogger(l *slog.Logger) *DAG {
+	d.logger = l
+	return d
+}
+
+// AddNode adds a named task that runs after the named dependencies. Nodes
+// can be added in any order; the graph is checked by Validate and Run.
+func (d *DAG) AddNode(name string, task TaskChainFn, dependsOn ...string) *DAG {
+	d.nodes = append(d.nodes, &dagNode{name: name, task: task, dependsOn: dependsOn})
+	return d
+}
+
+// Validate checks for empty or duplicate names, nil tasks, unknown
+// dependencies and cycles.
+func (d *DAG) Validate() error {
+	byName := make(map[string]*dagNode, len(d.nodes))
+	for _, n := range d.nodes {
+		if n.name == "" {
+			return fmt.Errorf("%w: node name must not be empty", ErrInvalidDAG)
+		}
+		if _, dup := byName[n.name]; dup {
+			return fmt.Errorf("%w: duplicate node %q", ErrInvalidDAG, n.name)
+		}
+		if n.task == nil {
+			return fmt.Errorf("%w: node %q", ErrNilTask, n.name)
+		}
+		byName[n.name] = n
+	}
+	for _, n := range d.nodes {
+		for _, dep := range n.dependsOn {
+			if _, ok := byName[dep]; !ok {
+				return fmt.Errorf("%w: node %q depends on unknown node %q", ErrInvalidDAG, n.name, dep)
+			}
+		}
+	}
+
+	// Depth-first search; a dependency that is still on the stack closes a cycle.
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(d.nodes))
+	var stack []string
+	var visit func(n *dagNode) error
+	visit = func(n *dagNode) error {
+		state[n.name] = visiting
+		stack = append(stack, n.name)
+		for _, dep := range n.dependsOn {
+			switch state[dep] {
+			case visiting:
+				start := 0
+				for i, name := range stack {
+					if name == dep {
+						start = i
+					}
+				}
+				cycle := append(append([]string(nil), stack[start:]...), dep)
+				return fmt.Errorf("%w: %s", ErrDAGCycle, strings.Join(cycle, " -> "))
+			case unvisited:
+				if err := visit(byName[dep]); err != nil {
+					return err
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[n.name] = visited
+		return nil
+	}
+	for _, n := range d.nodes {
+		if state[n.name] == unvisited {
+			if err := visit(n); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Run validates the graph and executes it. It returns the result of every
+// node, including failed and skipped ones, and a joined error naming each
+// failed node. A failed node does not stop branches that don't depend on it.
+func (d *DAG) Run(ctx context.Context) (map[string]NodeResult, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	results := make(map[string]NodeResult, len(d.nodes))
+	done := make(map[string]chan struct{}, len(d.nodes))
+	for _, n := range d.nodes {
+		results[n.name] = NodeResult{Status: NodePending}
+		done[n.name] = make(chan struct{})
+	}
+	setResult := func(name string, r NodeResult) {
+		mu.Lock()
+		results[name] = r
+		mu.Unlock()
+	}
+
+	var wg sync.WaitGroup
+	for _, n := range d.nodes {
+		wg.Add(1)
+		go func(n *dagNode) {
+			defer wg.Done()
+			defer close(done[n.name])
+
+			for _, dep := range n.dependsOn {
+				select {
+				case <-done[dep]:
+				case <-ctx.Done():
+					setResult(n.name, NodeResult{Status: NodeSkipped, Err: ctx.Err()})
+					return
+				}
+			}
+
+			var params []string
+			mu.Lock()
+			for _, dep := range n.dependsOn {
+				if r := results[dep]; r.Status != NodeSucceeded {
+					mu.Unlock()
+					setResult(n.name, NodeResult{Status: NodeSkipped, Err: fmt.Errorf("dependency %q %s", dep, r.Status)})
+					return
+				}
+			}
+			if len(n.dependsOn) > 0 {
+				params = []string{dependencyInput(n.dependsOn, results)}
+			}
+			results[n.name] = NodeResult{Status: NodeRunning}
+			mu.Unlock()
+
+			if err := ctx.Err(); err != nil {
+				setResult(n.name, NodeResult{Status: NodeSkipped, Err: err})
+				return
+			}
+
+			d.logDebug("node started", "node", n.name)
+			start := time.Now()
+			output, err := runNode(namedTaskContext(ctx, n.name), n, params)
+			r := NodeResult{Status: NodeSucceeded, Output: output, Err: err, Duration: time.Since(start)}
+			if err != nil {
+				r.Status = NodeFailed
+			}
+			d.logDebug("node finished", "node", n.name, "status", r.Status)
+			setResult(n.name, r)
+		}(n)
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, n := range d.nodes {
+		if r := results[n.name]; r.Status == NodeFailed {
+			errs = append(errs, fmt.Errorf("node %q: %w", n.name, r.Err))
+		}
+	}
+	if len(errs) == 0 {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// runNode calls the node's task, turning a panic into an error.
+func runNode(ctx context.Context, n *dagNode, params []string) (output string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("node %q panicked: %v", n.name, r)
+		}
+	}()
+	return n.task(ctx, params...)
+}
+
+// dependencyInput combines the outputs of a node's dependencies into one
+// context string. Callers hold the results lock.
+func dependencyInput(dependsOn []string, results map[string]NodeResult) string {
+	if len(dependsOn) == 1 {
+		return results[dependsOn[0]].Output
+	}
+	sections := make([]string, 0, len(dependsOn))
+	for _, dep := range dependsOn {
+		sections = append(sections, "### "+dep+"\n"+results[dep].Output)
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+func (d *DAG) logDebug(msg string, args ...any) {
+	if d.logger != nil {
+		d.logger.Debug(msg, args...)
+	}
+}
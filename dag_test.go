@@ -0,0 +1,170 @@
+package forza
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDAG_DiamondPassesDependencyOutputs(t *testing.T) {
+	var writerInput string
+	d := NewDAG().
+		AddNode("writer", func(ctx context.Context, params ...string) (string, error) {
+			writerInput = params[0]
+			return "article", nil
+		}, "researchA", "researchB").
+		AddNode("researchA", mockTask("facts about A")).
+		AddNode("researchB", mockTask("facts about B")).
+		AddNode("editor", mockTask("edited"), "writer")
+
+	results, err := d.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "### researchA\nfacts about A\n\n### researchB\nfacts about B"; writerInput != want {
+		t.Errorf("expected writer input %q, got %q", want, writerInput)
+	}
+	if got := results["editor"].Output; got != "edited [ctx:article]" {
+		t.Errorf("expected the single dependency output as context, got %q", got)
+	}
+	for name, r := range results {
+		if r.Status != NodeSucceeded {
+			t.Errorf("node %s: expected succeeded, got %s", name, r.Status)
+		}
+	}
+}
+
+func TestDAG_RunsIndependentBranchesConcurrently(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	slow := func(ctx context.Context, params ...string) (string, error) {
+		n := running.Add(1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		running.Add(-1)
+		return "ok", nil
+	}
+
+	d := NewDAG().AddNode("a", slow).AddNode("b", slow).AddNode("c", slow).AddNode("join", slow, "a", "b", "c")
+	if _, err := d.Run(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if maxRunning.Load() != 3 {
+		t.Errorf("expected the three roots to run together, max concurrency was %d", maxRunning.Load())
+	}
+}
+
+func TestDAG_FailureSkipsDependents(t *testing.T) {
+	d := NewDAG().
+		AddNode("fetch", mockErrorTask("boom")).
+		AddNode("summarize", mockTask("summary"), "fetch").
+		AddNode("publish", mockTask("published"), "summarize").
+		AddNode("unrelated", mockTask("fine"))
+
+	results, err := d.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), `node "fetch": boom`) {
+		t.Fatalf("expected the failed node in the error, got %v", err)
+	}
+
+	want := map[string]NodeStatus{
+		"fetch":     NodeFailed,
+		"summarize": NodeSkipped,
+		"publish":   NodeSkipped,
+		"unrelated": NodeSucceeded,
+	}
+	for name, status := range want {
+		if results[name].Status != status {
+			t.Errorf("node %s: expected %s, got %s", name, status, results[name].Status)
+		}
+	}
+	if !strings.Contains(results["summarize"].Err.Error(), `dependency "fetch" failed`) {
+		t.Errorf("expected the skip reason, got %v", results["summarize"].Err)
+	}
+}
+
+func TestDAG_Panics(t *testing.T) {
+	d := NewDAG().AddNode("bad", func(context.Context, ...string) (string, error) { panic("oops") })
+	results, err := d.Run(context.Background())
+	if err == nil || results["bad"].Status != NodeFailed || !strings.Contains(err.Error(), "panicked: oops") {
+		t.Errorf("expected the panic to fail the node, got %v (%s)", err, results["bad"].Status)
+	}
+}
+
+func TestDAG_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		dag  *DAG
+		want error
+		msg  string
+	}{
+		{"cycle", NewDAG().AddNode("a", mockTask("a"), "c").AddNode("b", mockTask("b"), "a").AddNode("c", mockTask("c"), "b"), ErrDAGCycle, "a -> c -> b -> a"},
+		{"self cycle", NewDAG().AddNode("a", mockTask("a"), "a"), ErrDAGCycle, "a -> a"},
+		{"unknown dependency", NewDAG().AddNode("a", mockTask("a"), "ghost"), ErrInvalidDAG, `unknown node "ghost"`},
+		{"duplicate", NewDAG().AddNode("a", mockTask("a")).AddNode("a", mockTask("b")), ErrInvalidDAG, `duplicate node "a"`},
+		{"nil task", NewDAG().AddNode("a", nil), ErrNilTask, `"a"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ran atomic.Bool
+			tt.dag.AddNode("probe", func(context.Context, ...string) (string, error) {
+				ran.Store(true)
+				return "", nil
+			})
+			_, err := tt.dag.Run(context.Background())
+			if !errors.Is(err, tt.want) || !strings.Contains(err.Error(), tt.msg) {
+				t.Errorf("expected %v containing %q, got %v", tt.want, tt.msg, err)
+			}
+			if ran.Load() {
+				t.Error("expected no node to run when validation fails")
+			}
+		})
+	}
+}
+
+func TestDAG_CancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	d := NewDAG().
+		AddNode("first", func(context.Context, ...string) (string, error) {
+			cancel()
+			return "done", nil
+		}).
+		AddNode("second", mockTask("never"), "first")
+
+	results, err := d.Run(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if results["second"].Status != NodeSkipped {
+		t.Errorf("expected the dependent node to be skipped, got %s", results["second"].Status)
+	}
+}
+
+func TestDAG_CostTrackerPerNode(t *testing.T) {
+	tracker := NewCostTracker()
+	ctx := ContextWithCostTracker(context.Background(), tracker)
+	record := func(ctx context.Context, params ...string) (string, error) {
+		recordUsage(ctx, OpenAIModels.GPT4oMini, Usage{PromptTokens: 100})
+		return "", nil
+	}
+
+	if _, err := NewDAG().AddNode("research", record).AddNode("write", record, "research").Run(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, c := range tracker.Children() {
+		names = append(names, c.Name())
+	}
+	if strings.Join(names, ",") != "research,write" {
+		t.Errorf("expected children named after nodes, got %v", names)
+	}
+	if tracker.Usage().PromptTokens != 200 {
+		t.Errorf("expected usage to roll up, got %+v", tracker.Usage())
+	}
+}
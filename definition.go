@@ -0,0 +1,336 @@
+package forza
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vitoraguila/forza/tools"
+	"gopkg.in/yaml.v3"
+)
+
+// Pipeline modes of a definition file.
+const (
+	PipelineModeChain      = "chain"
+	PipelineModeConcurrent = "concurrent"
+	PipelineModeSequential = "sequential"
+)
+
+// Definition describes agents, their model profiles and tools, and the
+// pipeline that runs them, so that prompts and flows can change without
+// recompiling:
+//
+//	default: fast
+//	profiles:
+//	  fast:
+//	    provider: openai
+//	    model: gpt-4o-mini
+//	    apiKeyEnv: OPENAI_API_KEY
+//	agents:
+//	  analyst:
+//	    role: Lead Market Analyst
+//	    backstory: you dissect online business landscapes
+//	    goal: providing in-depth insights
+//	    tools: [web_scraper]
+//	  writer:
+//	    role: Content Creator
+//	    backstory: you turn strategies into engaging stories
+//	    goal: writing social media posts
+//	tasks:
+//	  report:
+//	    agent: analyst
+//	    prompt: Give me a report about the electric car market.
+//	  post:
+//	    agent: writer
+//	    prompt: Write a social media post based on the report.
+//	pipeline:
+//	  mode: chain
+//	  tasks: [report, post]
+//
+// Profiles use the same keys as a profiles file (see Profiles). Agents
+// without a profile use the default one.
+type Definition struct {
+	Profiles `yaml:",inline"`
+	Agents   map[string]AgentSpec `yaml:"agents"`
+	Tasks    map[string]TaskSpec  `yaml:"tasks"`
+	Pipeline PipelineSpec         `yaml:"pipeline"`
+
+	root *yaml.Node
+}
+
+// AgentSpec describes an agent in a definition file. Tools are referenced by
+// name and supplied to Definition.Build.
+type AgentSpec struct {
+	Role      string   `yaml:"role"`
+	Backstory string   `yaml:"backstory"`
+	Goal      string   `yaml:"goal"`
+	Profile   string   `yaml:"profile"`
+	Tools     []string `yaml:"tools"`
+}
+
+// TaskSpec is a prompt given to an agent.
+type TaskSpec struct {
+	Agent  string `yaml:"agent"`
+	Prompt string `yaml:"prompt"`
+}
+
+// PipelineSpec lists the tasks to run, in order, and how to run them.
+type PipelineSpec struct {
+	Mode  string   `yaml:"mode"`
+	Tasks []string `yaml:"tasks"`
+}
+
+// ParseDefinition decodes and validates a YAML definition. Errors wrap
+// ErrInvalidDefinition and name the offending line and key.
+func ParseDefinition(data []byte) (*Definition, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDefinition, err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	d := &Definition{root: &root}
+	if err := dec.Decode(d); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDefinition, err)
+	}
+	if err := d.validate(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// LoadDefinition reads and parses a YAML definition file.
+func LoadDefinition(path string) (*Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDefinition, err)
+	}
+	return ParseDefinition(data)
+}
+
+func (d *Definition) validate() error {
+	if len(d.Profiles.Profiles) == 0 {
+		return d.errorAt("no profiles defined", "profiles")
+	}
+	if d.Default != "" {
+		if _, ok := d.Profiles.Profiles[d.Default]; !ok {
+			return d.errorAt(fmt.Sprintf("profile %q is not defined", d.Default), "default")
+		}
+	}
+	if len(d.Agents) == 0 {
+		return d.errorAt("no agents defined", "agents")
+	}
+
+	for _, name := range sortedKeys(d.Agents) {
+		a := d.Agents[name]
+		switch {
+		case a.Role == "":
+			return d.errorAt("role is required", "agents", name)
+		case a.Backstory == "":
+			return d.errorAt("backstory is required", "agents", name)
+		case a.Goal == "":
+			return d.errorAt("goal is required", "agents", name)
+		}
+		switch {
+		case a.Profile != "":
+			if _, ok := d.Profiles.Profiles[a.Profile]; !ok {
+				return d.errorAt(fmt.Sprintf("profile %q is not defined", a.Profile), "agents", name, "profile")
+			}
+		case d.Default == "" && len(d.Profiles.Profiles) > 1:
+			return d.errorAt("profile is required when the file has no default profile", "agents", name)
+		}
+	}
+
+	for _, name := range sortedKeys(d.Tasks) {
+		t := d.Tasks[name]
+		if _, ok := d.Agents[t.Agent]; !ok {
+			return d.errorAt(fmt.Sprintf("agent %q is not defined", t.Agent), "tasks", name, "agent")
+		}
+		if t.Prompt == "" {
+			return d.errorAt("prompt is required", "tasks", name)
+		}
+	}
+
+	switch d.Pipeline.Mode {
+	case PipelineModeChain, PipelineModeConcurrent, PipelineModeSequential:
+	default:
+		return d.errorAt(fmt.Sprintf("mode must be %q, %q or %q, got %q",
+			PipelineModeChain, PipelineModeConcurrent, PipelineModeSequential, d.Pipeline.Mode), "pipeline", "mode")
+	}
+	if len(d.Pipeline.Tasks) == 0 {
+		return d.errorAt("at least one task is required", "pipeline", "tasks")
+	}
+	for i, name := range d.Pipeline.Tasks {
+		if _, ok := d.Tasks[name]; !ok {
+			return d.errorAt(fmt.Sprintf("task %q is not defined", name), "pipeline", "tasks", strconv.Itoa(i))
+		}
+	}
+	return nil
+}
+
+// Build creates a task for every pipeline entry. Agents' tool names are
+// resolved against the given tools by Name().
+func (d *Definition) Build(available ...tools.Tool) (*DefinedPipeline, error) {
+	toolsByName := make(map[string]tools.Tool, len(available))
+	for _, t := range available {
+		toolsByName[t.Name()] = t
+	}
+
+	p := &DefinedPipeline{Pipeline: NewPipeline(), mode: d.Pipeline.Mode}
+	for i, taskName := range d.Pipeline.Tasks {
+		spec := d.Tasks[taskName]
+		agentSpec := d.Agents[spec.Agent]
+
+		profile := agentSpec.Profile
+		if profile == "" {
+			profile = d.Default
+		}
+		config, err := d.Profiles.Config(profile)
+		if err != nil {
+			return nil, d.wrapAt(err, "agents", spec.Agent)
+		}
+
+		var agentTools []tools.Tool
+		for j, toolName := range agentSpec.Tools {
+			t, ok := toolsByName[toolName]
+			if !ok {
+				return nil, d.errorAt(fmt.Sprintf("tool %q is not available", toolName), "agents", spec.Agent, "tools", strconv.Itoa(j))
+			}
+			agentTools = append(agentTools, t)
+		}
+
+		task, err := NewAgent().
+			WithRole(agentSpec.Role).
+			WithBackstory(agentSpec.Backstory).
+			WithGoal(agentSpec.Goal).
+			NewLLMTask(config)
+		if err != nil {
+			return nil, d.wrapAt(err, "pipeline", "tasks", strconv.Itoa(i))
+		}
+		task.WithUserPrompt(spec.Prompt)
+		if len(agentTools) > 0 {
+			task.WithTools(agentTools...)
+		}
+
+		p.AddTasks(task.Completion)
+		p.names = append(p.names, taskName)
+	}
+	return p, nil
+}
+
+// errorAt reports a validation error at the key found by path.
+func (d *Definition) errorAt(msg string, path ...string) error {
+	return fmt.Errorf("%w: line %d: %s: %s", ErrInvalidDefinition, nodeLine(d.root, path...), strings.Join(path, "."), msg)
+}
+
+// wrapAt annotates a build error with the location of the key found by path.
+func (d *Definition) wrapAt(err error, path ...string) error {
+	return fmt.Errorf("line %d: %s: %w", nodeLine(d.root, path...), strings.Join(path, "."), err)
+}
+
+// nodeLine returns the line of the deepest node of path that exists in the
+// document, or 0 for an empty document. Numeric elements index sequences.
+func nodeLine(root *yaml.Node, path ...string) int {
+	if root == nil || len(root.Content) == 0 {
+		return 0
+	}
+	n := root.Content[0]
+	line := n.Line
+	for _, key := range path {
+		var next *yaml.Node
+		switch n.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				if n.Content[i].Value == key {
+					line = n.Content[i].Line
+					next = n.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(key); err == nil && i < len(n.Content) {
+				next = n.Content[i]
+				line = next.Line
+			}
+		}
+		if next == nil {
+			return line
+		}
+		n = next
+	}
+	return line
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// DefinedPipeline is a Pipeline built from a Definition that remembers its
+// mode and task names.
+type DefinedPipeline struct {
+	*Pipeline
+	mode  string
+	names []string
+}
+
+// TaskNames returns the names of the pipeline's tasks in order.
+func (p *DefinedPipeline) TaskNames() []string {
+	return append([]string(nil), p.names...)
+}
+
+// Mode returns the pipeline's run mode, one of the PipelineMode constants.
+func (p *DefinedPipeline) Mode() string {
+	return p.mode
+}
+
+// Run executes the pipeline in its defined mode. Concurrent and sequential
+// runs return one result per task; a chain returns only its final result.
+//
+// An optional input is passed as context to every task that would otherwise
+// receive none: the first task of a chain, or every task of a concurrent or
+// sequential run.
+func (p *DefinedPipeline) Run(ctx context.Context, input ...string) ([]string, error) {
+	if len(input) > 1 {
+		return nil, ErrTooManyArgs
+	}
+	run := *p.Pipeline
+	if len(input) == 1 {
+		run.tasks = make([]TaskChainFn, len(p.tasks))
+		for i, task := range p.tasks {
+			run.tasks[i] = withDefaultContext(task, input[0])
+		}
+	}
+
+	switch p.mode {
+	case PipelineModeChain:
+		result, err := p.CreateChain(run.tasks...)(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return []string{result}, nil
+	case PipelineModeConcurrent:
+		return run.RunConcurrently(ctx)
+	default:
+		return run.RunSequentially(ctx)
+	}
+}
+
+// withDefaultContext passes input as context when task is called without one.
+func withDefaultContext(task TaskChainFn, input string) TaskChainFn {
+	return func(ctx context.Context, params ...string) (string, error) {
+		if len(params) == 0 {
+			params = []string{input}
+		}
+		return task(ctx, params...)
+	}
+}
@@ -0,0 +1,164 @@
+package forza
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const testDefinition = `
+profiles:
+  local:
+    provider: ollama
+    model: llama3.1
+    endpoint: %s
+agents:
+  analyst:
+    role: Analyst
+    backstory: you analyse markets
+    goal: insights
+    tools: [lookup]
+  writer:
+    role: Writer
+    backstory: you write posts
+    goal: engagement
+tasks:
+  report:
+    agent: analyst
+    prompt: Write a report.
+  post:
+    agent: writer
+    prompt: Write a post.
+pipeline:
+  mode: %s
+  tasks: [report, post]
+`
+
+// echoServer answers every chat completion with the last user message.
+func echoServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openai.ChatCompletionRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		last := req.Messages[len(req.Messages)-1].Content
+		json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: "echo: " + last}}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDefinition_BuildAndRun(t *testing.T) {
+	server := echoServer(t)
+
+	tests := []struct {
+		mode string
+		want []string
+	}{
+		{PipelineModeSequential, []string{"echo: Write a report.", "echo: Write a post."}},
+		{PipelineModeConcurrent, []string{"echo: Write a report.", "echo: Write a post."}},
+		{PipelineModeChain, []string{"echo: Write a post." + contextSeparator + "echo: Write a report."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			d, err := ParseDefinition([]byte(fmt.Sprintf(testDefinition, server.URL+"/v1", tt.mode)))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			p, err := d.Build(&mockTool{name: "lookup", desc: "look things up"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if names := p.TaskNames(); !reflect.DeepEqual(names, []string{"report", "post"}) {
+				t.Errorf("unexpected task names %v", names)
+			}
+
+			results, err := p.Run(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(results, tt.want) {
+				t.Errorf("expected %q, got %q", tt.want, results)
+			}
+		})
+	}
+}
+
+func TestParseDefinition_ErrorLocations(t *testing.T) {
+	valid := fmt.Sprintf(testDefinition, "http://localhost:11434/v1", "chain")
+
+	tests := []struct {
+		name    string
+		replace [2]string
+		want    string
+	}{
+		{"unknown agent", [2]string{"agent: writer", "agent: editor"}, `line 22: tasks.post.agent: agent "editor" is not defined`},
+		{"missing goal", [2]string{"    goal: engagement\n", ""}, "line 13: agents.writer: goal is required"},
+		{"bad mode", [2]string{"mode: chain", "mode: parallel"}, "line 25: pipeline.mode: mode must be"},
+		{"unknown task", [2]string{"[report, post]", "[report, publish]"}, `line 26: pipeline.tasks.1: task "publish" is not defined`},
+		{"unknown key", [2]string{"prompt: Write a post.", "promt: Write a post."}, "line 23: field promt not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDefinition([]byte(strings.Replace(valid, tt.replace[0], tt.replace[1], 1)))
+			if !errors.Is(err, ErrInvalidDefinition) {
+				t.Fatalf("expected ErrInvalidDefinition, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %q", tt.want, err.Error())
+			}
+		})
+	}
+}
+
+func TestDefinition_BuildErrors(t *testing.T) {
+	d, err := ParseDefinition([]byte(fmt.Sprintf(testDefinition, "http://localhost:11434/v1", "chain")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = d.Build()
+	if !errors.Is(err, ErrInvalidDefinition) || !strings.Contains(err.Error(), `line 12: agents.analyst.tools.0: tool "lookup" is not available`) {
+		t.Errorf("expected a missing tool error, got %v", err)
+	}
+
+	d.Profiles.Profiles["local"] = Profile{Provider: ProviderOpenAi, Model: OpenAIModels.GPT4oMini, APIKeyEnv: "TEST_FORZA_UNSET_KEY"}
+	_, err = d.Build(&mockTool{name: "lookup"})
+	if !errors.Is(err, ErrMissingAPIKey) || !strings.Contains(err.Error(), "line 8: agents.analyst") {
+		t.Errorf("expected a located ErrMissingAPIKey, got %v", err)
+	}
+}
+
+func TestDefinedPipeline_RunWithInput(t *testing.T) {
+	server := echoServer(t)
+
+	d, err := ParseDefinition([]byte(fmt.Sprintf(testDefinition, server.URL+"/v1", PipelineModeSequential)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p, err := d.Build(&mockTool{name: "lookup"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	results, err := p.Run(context.Background(), "EV sales doubled")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, r := range results {
+		if !strings.HasSuffix(r, contextSeparator+"EV sales doubled") {
+			t.Errorf("task %d: expected the input as context, got %q", i, r)
+		}
+	}
+	if _, err := p.Run(context.Background(), "a", "b"); !errors.Is(err, ErrTooManyArgs) {
+		t.Errorf("expected ErrTooManyArgs, got %v", err)
+	}
+}
@@ -0,0 +1,268 @@
+package forza
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const anthropicModelsURL = "https://api.anthropic.com/v1/models"
+
+// discoveryPageSize is the page size requested from paginated model lists.
+const discoveryPageSize = 1000
+
+// discoveryHTTPClient returns the HTTP client used for model discovery.
+// Tests replace it to redirect requests to a mock server.
+var discoveryHTTPClient = func(c *LLMConfig) *http.Client {
+	return &http.Client{Timeout: c.timeout}
+}
+
+// ListModels asks the config's provider which models its credentials can
+// access: OpenAI and Azure OpenAI /v1/models, Anthropic /v1/models, Gemini
+// models.list (only models supporting generateContent) and Ollama /api/tags.
+// The result is sorted and never cached; see WithModelDiscovery for cached
+// validation.
+func ListModels(ctx context.Context, c *LLMConfig) ([]string, error) {
+	var (
+		models []string
+		err    error
+	)
+	switch c.provider {
+	case ProviderOpenAi, ProviderAzure:
+		models, err = listOpenAIModels(ctx, c)
+	case ProviderAnthropic:
+		models, err = listAnthropicModels(ctx, c)
+	case ProviderGemini:
+		models, err = listGeminiModels(ctx, c)
+	case ProviderOllama:
+		models, err = listOllamaModels(ctx, c)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrProviderNotFound, c.provider)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(models)
+	return models, nil
+}
+
+func listOpenAIModels(ctx context.Context, c *LLMConfig) ([]string, error) {
+	config, err := openAIClientConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	config.HTTPClient = discoveryHTTPClient(c)
+
+	list, err := openai.NewClientWithConfig(config).ListModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrModelDiscoveryFailed, err)
+	}
+	models := make([]string, 0, len(list.Models))
+	for _, m := range list.Models {
+		models = append(models, m.ID)
+	}
+	return models, nil
+}
+
+type anthropicModelList struct {
+	Data []struct {
+		ID string `json:"id"`
+	} `json:"data"`
+	HasMore bool   `json:"has_more"`
+	LastID  string `json:"last_id"`
+}
+
+func listAnthropicModels(ctx context.Context, c *LLMConfig) ([]string, error) {
+	if c.credentials.apiKey == "" {
+		return nil, fmt.Errorf("%w: Anthropic API key", ErrMissingAPIKey)
+	}
+	header := http.Header{}
+	header.Set("x-api-key", c.credentials.apiKey)
+	header.Set("anthropic-version", anthropicAPIVersion)
+
+	var models []string
+	afterID := ""
+	for {
+		query := url.Values{"limit": {fmt.Sprint(discoveryPageSize)}}
+		if afterID != "" {
+			query.Set("after_id", afterID)
+		}
+		var page anthropicModelList
+		if err := getDiscoveryJSON(ctx, c, anthropicModelsURL+"?"+query.Encode(), header, &page); err != nil {
+			return nil, err
+		}
+		for _, m := range page.Data {
+			models = append(models, m.ID)
+		}
+		if !page.HasMore || page.LastID == "" {
+			return models, nil
+		}
+		afterID = page.LastID
+	}
+}
+
+type geminiModelList struct {
+	Models []struct {
+		Name                       string   `json:"name"`
+		SupportedGenerationMethods []string `json:"supportedGenerationMethods"`
+	} `json:"models"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+func listGeminiModels(ctx context.Context, c *LLMConfig) ([]string, error) {
+	if c.credentials.apiKey == "" {
+		return nil, fmt.Errorf("%w: Gemini API key", ErrMissingAPIKey)
+	}
+	header := http.Header{}
+	header.Set("x-goog-api-key", c.credentials.apiKey)
+
+	var models []string
+	pageToken := ""
+	for {
+		query := url.Values{"pageSize": {fmt.Sprint(discoveryPageSize)}}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		var page geminiModelList
+		if err := getDiscoveryJSON(ctx, c, geminiAPIBaseURL+"?"+query.Encode(), header, &page); err != nil {
+			return nil, err
+		}
+		for _, m := range page.Models {
+			for _, method := range m.SupportedGenerationMethods {
+				if method == "generateContent" {
+					models = append(models, strings.TrimPrefix(m.Name, "models/"))
+					break
+				}
+			}
+		}
+		if page.NextPageToken == "" {
+			return models, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+type ollamaTagList struct {
+	Models []struct {
+		Name string `json:"name"`
+	} `json:"models"`
+}
+
+func listOllamaModels(ctx context.Context, c *LLMConfig) ([]string, error) {
+	endpoint := c.credentials.endpoint
+	if endpoint == "" {
+		endpoint = defaultOllamaEndpoint
+	}
+	// Completions go through the OpenAI-compatible /v1 API, but tags live on
+	// the native API.
+	base := strings.TrimSuffix(strings.TrimSuffix(endpoint, "/"), "/v1")
+
+	var tags ollamaTagList
+	if err := getDiscoveryJSON(ctx, c, base+"/api/tags", nil, &tags); err != nil {
+		return nil, err
+	}
+	models := make([]string, 0, len(tags.Models))
+	for _, m := range tags.Models {
+		models = append(models, m.Name)
+	}
+	return models, nil
+}
+
+// getDiscoveryJSON performs a GET request and decodes the JSON response into v.
+func getDiscoveryJSON(ctx context.Context, c *LLMConfig, rawURL string, header http.Header, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return fmt.Errorf("%w: failed to create request: %v", ErrModelDiscoveryFailed, err)
+	}
+	for k, vals := range header {
+		req.Header[k] = vals
+	}
+
+	resp, err := discoveryHTTPClient(c).Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrModelDiscoveryFailed, c.provider, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return fmt.Errorf("%w: failed to read response: %v", ErrModelDiscoveryFailed, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s: unexpected status %d: %s", ErrModelDiscoveryFailed, c.provider, resp.StatusCode, string(body))
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("%w: failed to parse response: %v", ErrModelDiscoveryFailed, err)
+	}
+	return nil
+}
+
+// modelCache keeps discovered model lists per provider and credentials.
+type modelCache struct {
+	mu      sync.Mutex
+	entries map[string]modelCacheEntry
+	now     func() time.Time
+}
+
+type modelCacheEntry struct {
+	models  []string
+	fetched time.Time
+}
+
+var discoveredModels = &modelCache{
+	entries: make(map[string]modelCacheEntry),
+	now:     time.Now,
+}
+
+// get returns the cached list for the config's account, calling ListModels
+// when there is none or it is older than the config's discovery TTL.
+func (m *modelCache) get(ctx context.Context, c *LLMConfig) ([]string, error) {
+	key := credentialsKey(c)
+
+	m.mu.Lock()
+	entry, ok := m.entries[key]
+	m.mu.Unlock()
+	if ok && m.now().Sub(entry.fetched) < c.discoveryTTL {
+		return entry.models, nil
+	}
+
+	models, err := ListModels(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	m.entries[key] = modelCacheEntry{models: models, fetched: m.now()}
+	m.mu.Unlock()
+	return models, nil
+}
+
+// checkDiscoveredModel validates the config's model against the provider's
+// live model list.
+func checkDiscoveredModel(c *LLMConfig) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	models, err := discoveredModels.get(ctx, c)
+	if err != nil {
+		return err
+	}
+	for _, m := range models {
+		if m == c.model {
+			return nil
+		}
+		// Ollama resolves an untagged name to its ":latest" tag.
+		if c.provider == ProviderOllama && m == c.model+":latest" {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: model %q is not available to these %s credentials. Available models: %v", ErrModelNotFound, c.model, c.provider, models)
+}
@@ -0,0 +1,231 @@
+package forza
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// useDiscoveryServer routes model discovery requests to server and gives the
+// test a fresh discovery cache.
+func useDiscoveryServer(t *testing.T, server *httptest.Server) {
+	t.Helper()
+	origClient, origCache := discoveryHTTPClient, discoveredModels
+	discoveryHTTPClient = func(*LLMConfig) *http.Client {
+		return &http.Client{Transport: &testRewriteTransport{baseURL: server.URL}}
+	}
+	discoveredModels = &modelCache{entries: make(map[string]modelCacheEntry), now: time.Now}
+	t.Cleanup(func() {
+		discoveryHTTPClient, discoveredModels = origClient, origCache
+	})
+}
+
+func TestListModels_OpenAI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("expected bearer auth, got %q", r.Header.Get("Authorization"))
+		}
+		w.Write([]byte(`{"object":"list","data":[{"id":"gpt-4o"},{"id":"gpt-4.1"}]}`))
+	}))
+	defer server.Close()
+	useDiscoveryServer(t, server)
+
+	models, err := ListModels(context.Background(), NewLLMConfig().WithProvider(ProviderOpenAi).WithOpenAiCredentials("key"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"gpt-4.1", "gpt-4o"}; !reflect.DeepEqual(models, want) {
+		t.Errorf("expected %v, got %v", want, models)
+	}
+}
+
+func TestListModels_AnthropicPaginates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "key" || r.Header.Get("anthropic-version") != anthropicAPIVersion {
+			t.Errorf("missing Anthropic headers: %v", r.Header)
+		}
+		switch r.URL.Query().Get("after_id") {
+		case "":
+			w.Write([]byte(`{"data":[{"id":"claude-b"}],"has_more":true,"last_id":"claude-b"}`))
+		case "claude-b":
+			w.Write([]byte(`{"data":[{"id":"claude-a"}],"has_more":false,"last_id":"claude-a"}`))
+		default:
+			t.Errorf("unexpected after_id %q", r.URL.Query().Get("after_id"))
+		}
+	}))
+	defer server.Close()
+	useDiscoveryServer(t, server)
+
+	models, err := ListModels(context.Background(), NewLLMConfig().WithProvider(ProviderAnthropic).WithAnthropicCredentials("key"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"claude-a", "claude-b"}; !reflect.DeepEqual(models, want) {
+		t.Errorf("expected %v, got %v", want, models)
+	}
+}
+
+func TestListModels_GeminiFiltersAndPaginates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-goog-api-key") != "key" {
+			t.Errorf("expected x-goog-api-key header")
+		}
+		if r.URL.Query().Get("pageToken") == "" {
+			json.NewEncoder(w).Encode(map[string]any{
+				"models": []map[string]any{
+					{"name": "models/gemini-2.5-flash", "supportedGenerationMethods": []string{"generateContent", "countTokens"}},
+					{"name": "models/text-embedding-004", "supportedGenerationMethods": []string{"embedContent"}},
+				},
+				"nextPageToken": "next",
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"models": []map[string]any{
+				{"name": "models/gemini-2.5-pro", "supportedGenerationMethods": []string{"generateContent"}},
+			},
+		})
+	}))
+	defer server.Close()
+	useDiscoveryServer(t, server)
+
+	models, err := ListModels(context.Background(), NewLLMConfig().WithProvider(ProviderGemini).WithGeminiCredentials("key"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"gemini-2.5-flash", "gemini-2.5-pro"}; !reflect.DeepEqual(models, want) {
+		t.Errorf("expected %v, got %v", want, models)
+	}
+}
+
+func TestListModels_Ollama(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			t.Errorf("expected /api/tags, got %q", r.URL.Path)
+		}
+		w.Write([]byte(`{"models":[{"name":"llama3:latest"},{"name":"qwen2.5:7b"}]}`))
+	}))
+	defer server.Close()
+
+	config := NewLLMConfig().WithProvider(ProviderOllama).WithOllamaCredentials(server.URL + "/v1")
+	models, err := ListModels(context.Background(), config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"llama3:latest", "qwen2.5:7b"}; !reflect.DeepEqual(models, want) {
+		t.Errorf("expected %v, got %v", want, models)
+	}
+}
+
+func TestListModels_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"message":"invalid key"}}`))
+	}))
+	defer server.Close()
+	useDiscoveryServer(t, server)
+
+	_, err := ListModels(context.Background(), NewLLMConfig().WithProvider(ProviderAnthropic).WithAnthropicCredentials("bad"))
+	if !errors.Is(err, ErrModelDiscoveryFailed) {
+		t.Errorf("expected ErrModelDiscoveryFailed, got %v", err)
+	}
+
+	_, err = ListModels(context.Background(), NewLLMConfig().WithProvider(ProviderGemini))
+	if !errors.Is(err, ErrMissingAPIKey) {
+		t.Errorf("expected ErrMissingAPIKey, got %v", err)
+	}
+
+	_, err = ListModels(context.Background(), NewLLMConfig().WithProvider("unknown"))
+	if !errors.Is(err, ErrProviderNotFound) {
+		t.Errorf("expected ErrProviderNotFound, got %v", err)
+	}
+}
+
+func TestNewLLMTask_ModelDiscovery(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"data":[{"id":"claude-sonnet-5-20261001"}],"has_more":false}`))
+	}))
+	defer server.Close()
+	useDiscoveryServer(t, server)
+
+	agent := NewAgent().WithRole("r").WithBackstory("b").WithGoal("g")
+	config := func(model string) *LLMConfig {
+		return NewLLMConfig().
+			WithProvider(ProviderAnthropic).
+			WithModel(model).
+			WithAnthropicCredentials("key").
+			WithModelDiscovery(time.Hour)
+	}
+
+	if _, err := agent.NewLLMTask(config("claude-sonnet-5-20261001")); err != nil {
+		t.Fatalf("expected a discovered model to be accepted, got %v", err)
+	}
+	// A catalog model the account cannot access is rejected.
+	if _, err := agent.NewLLMTask(config(AnthropicModels.Claude4Sonnet)); !errors.Is(err, ErrModelNotFound) {
+		t.Errorf("expected ErrModelNotFound, got %v", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected the model list to be cached, got %d requests", n)
+	}
+}
+
+func TestNewLLMTask_ModelDiscoveryOllamaLatestTag(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"models":[{"name":"llama3:latest"}]}`))
+	}))
+	defer server.Close()
+	useDiscoveryServer(t, server)
+
+	agent := NewAgent().WithRole("r").WithBackstory("b").WithGoal("g")
+	config := func(model string) *LLMConfig {
+		return NewLLMConfig().
+			WithProvider(ProviderOllama).
+			WithModel(model).
+			WithOllamaCredentials(server.URL).
+			WithModelDiscovery(time.Minute)
+	}
+
+	if _, err := agent.NewLLMTask(config("llama3")); err != nil {
+		t.Errorf("expected llama3 to match llama3:latest, got %v", err)
+	}
+	if _, err := agent.NewLLMTask(config("mistral")); !errors.Is(err, ErrModelNotFound) {
+		t.Errorf("expected a model that is not pulled to be rejected, got %v", err)
+	}
+}
+
+func TestModelCache_Expires(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"models":[]}`))
+	}))
+	defer server.Close()
+	useDiscoveryServer(t, server)
+
+	now := time.Unix(0, 0)
+	discoveredModels.now = func() time.Time { return now }
+	config := NewLLMConfig().WithProvider(ProviderOllama).WithModelDiscovery(time.Minute)
+
+	discoveredModels.get(context.Background(), config)
+	now = now.Add(30 * time.Second)
+	discoveredModels.get(context.Background(), config)
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected a cache hit within the TTL, got %d requests", n)
+	}
+	now = now.Add(time.Minute)
+	discoveredModels.get(context.Background(), config)
+	if n := calls.Load(); n != 2 {
+		t.Errorf("expected a refresh after the TTL, got %d requests", n)
+	}
+}
@@ -19,4 +19,21 @@ var (
 	ErrMaxToolRoundsExceeded = errors.New("maximum tool call rounds exceeded")
 	ErrInvalidConfig         = errors.New("invalid LLM configuration")
 	ErrResponseTooLarge      = errors.New("response body exceeds maximum allowed size")
+	ErrAllProvidersFailed    = errors.New("all fallback providers failed")
+	ErrCircuitOpen           = errors.New("circuit breaker is open")
+	ErrBudgetExceeded        = errors.New("spend budget exceeded")
+	ErrContextOverflow       = errors.New("prompt does not fit the model's context window")
+	ErrModelDiscoveryFailed  = errors.New("listing provider models failed")
+	ErrProfileNotFound       = errors.New("configuration profile not found")
+	ErrInvalidDefinition     = errors.New("invalid pipeline definition")
+	ErrInvalidDAG            = errors.New("invalid task graph")
+	ErrDAGCycle              = errors.New("task graph contains a cycle")
+	ErrTaskTimeout           = errors.New("task timed out")
+	ErrInvalidOutput         = errors.New("task output could not be decoded")
+	ErrInvalidTemplate       = errors.New("invalid prompt template")
+	ErrMissingVariable       = errors.New("prompt template variable has no value")
+	ErrInvalidRouter         = errors.New("invalid router")
+	ErrUnknownRoute          = errors.New("router chose an unknown route")
+	ErrMapReduceFailed       = errors.New("map-reduce failed")
+	ErrPlanIncomplete        = errors.New("plan did not complete")
 )
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/vitoraguila/forza"
+)
+
+func main() {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	def, err := forza.LoadDefinition("pipeline.yaml")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	pipeline, err := def.Build()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	results, err := pipeline.Run(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("Chain result: ", results[0])
+}
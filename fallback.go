@@ -0,0 +1,184 @@
+package forza
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/vitoraguila/forza/tools"
+)
+
+// FallbackEntry pairs an Agent with the configuration of one backend in a
+// fallback chain.
+type FallbackEntry struct {
+	Agent  *Agent
+	Config *LLMConfig
+}
+
+// fallbackBackend is one provider in a FallbackAgent, guarded by its own
+// circuit breaker.
+type fallbackBackend struct {
+	provider string
+	model    string
+	task     LLMAgent
+	breaker  *circuitBreaker
+}
+
+// FallbackAgent is an LLMAgent that tries an ordered list of backends,
+// moving to the next one when a backend fails with a retryable error, a
+// timeout, or one of the configured error classes.
+type FallbackAgent struct {
+	backends   []*fallbackBackend
+	fallbackOn []error
+
+	mu           sync.Mutex
+	lastProvider string
+	lastModel    string
+}
+
+var _ LLMAgent = (*FallbackAgent)(nil)
+
+// NewFallbackAgent builds a FallbackAgent from the given entries, in order of
+// preference. Each entry is validated like Agent.NewLLMTask.
+func NewFallbackAgent(entries ...FallbackEntry) (*FallbackAgent, error) {
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w: at least one fallback entry is required", ErrInvalidConfig)
+	}
+
+	f := &FallbackAgent{}
+	for i, e := range entries {
+		if e.Agent == nil || e.Config == nil {
+			return nil, fmt.Errorf("%w: fallback entry %d needs an Agent and a Config", ErrInvalidConfig, i)
+		}
+		task, err := e.Agent.NewLLMTask(e.Config)
+		if err != nil {
+			return nil, fmt.Errorf("fallback entry %d: %w", i, err)
+		}
+		f.backends = append(f.backends, &fallbackBackend{
+			provider: e.Config.provider,
+			model:    e.Config.model,
+			task:     task,
+			breaker:  newCircuitBreaker(defaultBreakerThreshold, defaultBreakerCooldown),
+		})
+	}
+	return f, nil
+}
+
+// WithFallbackOn adds error classes (matched with errors.Is) that should also
+// trigger a fallback to the next backend.
+func (f *FallbackAgent) WithFallbackOn(errs ...error) *FallbackAgent {
+	f.fallbackOn = append(f.fallbackOn, errs...)
+	return f
+}
+
+// WithCircuitBreaker configures every backend's circuit breaker: it opens
+// after failureThreshold consecutive failures and allows a trial request
+// once cooldown has elapsed.
+func (f *FallbackAgent) WithCircuitBreaker(failureThreshold int, cooldown time.Duration) *FallbackAgent {
+	for _, b := range f.backends {
+		b.breaker = newCircuitBreaker(failureThreshold, cooldown)
+	}
+	return f
+}
+
+// AnsweredBy returns the provider and model of the backend that produced the
+// most recent successful completion.
+func (f *FallbackAgent) AnsweredBy() (provider, model string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lastProvider, f.lastModel
+}
+
+func (f *FallbackAgent) WithUserPrompt(prompt string) {
+	for _, b := range f.backends {
+		b.task.WithUserPrompt(prompt)
+	}
+}
+
+func (f *FallbackAgent) WithTools(t ...tools.Tool) {
+	for _, b := range f.backends {
+		b.task.WithTools(t...)
+	}
+}
+
+func (f *FallbackAgent) AddCustomTools(name string, description string, params FunctionShape, fn func(param string) (string, error)) {
+	for _, b := range f.backends {
+		b.task.AddCustomTools(name, description, params, fn)
+	}
+}
+
+// Completion runs the completion against each backend in turn until one
+// succeeds. Errors that don't qualify for a fallback are returned as-is.
+func (f *FallbackAgent) Completion(ctx context.Context, params ...string) (string, error) {
+	var errs []error
+	for _, b := range f.backends {
+		if !b.breaker.allow() {
+			errs = append(errs, fmt.Errorf("%s/%s: %w", b.provider, b.model, ErrCircuitOpen))
+			continue
+		}
+
+		result, err := b.task.Completion(ctx, params...)
+		if err == nil {
+			b.breaker.success()
+			f.mu.Lock()
+			f.lastProvider, f.lastModel = b.provider, b.model
+			f.mu.Unlock()
+			return result, nil
+		}
+
+		if ctx.Err() != nil {
+			b.breaker.release()
+			return "", err
+		}
+		if !f.shouldFallback(err) {
+			// The backend answered; the request itself was at fault.
+			b.breaker.success()
+			return "", err
+		}
+
+		b.breaker.failure()
+		errs = append(errs, fmt.Errorf("%s/%s: %w", b.provider, b.model, err))
+	}
+	return "", fmt.Errorf("%w: %w", ErrAllProvidersFailed, errors.Join(errs...))
+}
+
+// shouldFallback reports whether err indicates that the backend, rather than
+// the request, is at fault.
+func (f *FallbackAgent) shouldFallback(err error) bool {
+	for _, target := range f.fallbackOn {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return isTransientError(err)
+}
+
+// isTransientError reports whether err is a retryable HTTP status, a timeout
+// or a network failure from any provider.
+func isTransientError(err error) bool {
+	var re *retryableError
+	if errors.As(err, &re) {
+		return retryableStatusCodes[re.statusCode]
+	}
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return retryableStatusCodes[apiErr.HTTPStatusCode]
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return retryableStatusCodes[reqErr.HTTPStatusCode]
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,267 @@
+package forza
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/vitoraguila/forza/tools"
+)
+
+// stubAgent is a scriptable LLMAgent for tests.
+type stubAgent struct {
+	prompt   string
+	tools    []string
+	calls    int
+	complete func(ctx context.Context, params ...string) (string, error)
+}
+
+func (s *stubAgent) Completion(ctx context.Context, params ...string) (string, error) {
+	s.calls++
+	return s.complete(ctx, params...)
+}
+
+func (s *stubAgent) AddCustomTools(name string, description string, params FunctionShape, fn func(param string) (string, error)) {
+	s.tools = append(s.tools, name)
+}
+
+func (s *stubAgent) WithUserPrompt(prompt string) { s.prompt = prompt }
+
+func (s *stubAgent) WithTools(t ...tools.Tool) {
+	for _, tool := range t {
+		s.tools = append(s.tools, tool.Name())
+	}
+}
+
+func newStubFallback(agents ...*stubAgent) *FallbackAgent {
+	f := &FallbackAgent{}
+	for i, a := range agents {
+		f.backends = append(f.backends, &fallbackBackend{
+			provider: fmt.Sprintf("provider-%d", i),
+			model:    fmt.Sprintf("model-%d", i),
+			task:     a,
+			breaker:  newCircuitBreaker(defaultBreakerThreshold, defaultBreakerCooldown),
+		})
+	}
+	return f
+}
+
+func transientErr() error {
+	return &retryableError{err: ErrCompletionFailed, statusCode: http.StatusServiceUnavailable}
+}
+
+func TestNewFallbackAgent_Validation(t *testing.T) {
+	if _, err := NewFallbackAgent(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for no entries, got %v", err)
+	}
+
+	agent := NewAgent().WithRole("r").WithBackstory("b").WithGoal("g")
+	bad := NewLLMConfig().WithProvider(ProviderAnthropic).WithModel("no-such-model")
+	_, err := NewFallbackAgent(FallbackEntry{Agent: agent, Config: bad})
+	if !errors.Is(err, ErrModelNotFound) {
+		t.Errorf("expected ErrModelNotFound, got %v", err)
+	}
+
+	if _, err := NewFallbackAgent(FallbackEntry{Agent: agent}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for missing config, got %v", err)
+	}
+}
+
+func TestFallbackAgent_FallsThroughOnTransientError(t *testing.T) {
+	first := &stubAgent{complete: func(ctx context.Context, params ...string) (string, error) {
+		return "", transientErr()
+	}}
+	second := &stubAgent{complete: func(ctx context.Context, params ...string) (string, error) {
+		return "from second: " + params[0], nil
+	}}
+	f := newStubFallback(first, second)
+
+	result, err := f.Completion(context.Background(), "ctx")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "from second: ctx" {
+		t.Errorf("expected second backend's answer with context, got %q", result)
+	}
+	if provider, model := f.AnsweredBy(); provider != "provider-1" || model != "model-1" {
+		t.Errorf("expected provider-1/model-1 to answer, got %s/%s", provider, model)
+	}
+}
+
+func TestFallbackAgent_StopsOnNonTransientError(t *testing.T) {
+	badRequest := &retryableError{err: ErrCompletionFailed, statusCode: http.StatusBadRequest}
+	first := &stubAgent{complete: func(ctx context.Context, params ...string) (string, error) {
+		return "", badRequest
+	}}
+	second := &stubAgent{complete: func(ctx context.Context, params ...string) (string, error) {
+		return "unreachable", nil
+	}}
+	f := newStubFallback(first, second)
+
+	_, err := f.Completion(context.Background())
+	if !errors.Is(err, badRequest) {
+		t.Errorf("expected the original error, got %v", err)
+	}
+	if second.calls != 0 {
+		t.Error("expected no fallback for a non-transient error")
+	}
+}
+
+func TestFallbackAgent_WithFallbackOn(t *testing.T) {
+	first := &stubAgent{complete: func(ctx context.Context, params ...string) (string, error) {
+		return "", fmt.Errorf("%w: nope", ErrMaxToolRoundsExceeded)
+	}}
+	second := &stubAgent{complete: func(ctx context.Context, params ...string) (string, error) {
+		return "ok", nil
+	}}
+	f := newStubFallback(first, second).WithFallbackOn(ErrMaxToolRoundsExceeded)
+
+	result, err := f.Completion(context.Background())
+	if err != nil || result != "ok" {
+		t.Errorf("expected fallback on configured error class, got %q, %v", result, err)
+	}
+}
+
+func TestFallbackAgent_AllFail(t *testing.T) {
+	failing := func(ctx context.Context, params ...string) (string, error) { return "", transientErr() }
+	f := newStubFallback(&stubAgent{complete: failing}, &stubAgent{complete: failing})
+
+	_, err := f.Completion(context.Background())
+	if !errors.Is(err, ErrAllProvidersFailed) {
+		t.Errorf("expected ErrAllProvidersFailed, got %v", err)
+	}
+	if !errors.Is(err, ErrCompletionFailed) {
+		t.Errorf("expected underlying errors to be preserved, got %v", err)
+	}
+}
+
+func TestFallbackAgent_CircuitBreakerSkipsBackend(t *testing.T) {
+	first := &stubAgent{complete: func(ctx context.Context, params ...string) (string, error) {
+		return "", transientErr()
+	}}
+	second := &stubAgent{complete: func(ctx context.Context, params ...string) (string, error) {
+		return "ok", nil
+	}}
+	f := newStubFallback(first, second).WithCircuitBreaker(2, time.Minute)
+
+	for i := 0; i < 5; i++ {
+		if _, err := f.Completion(context.Background()); err != nil {
+			t.Fatalf("call %d: unexpected error: %v", i+1, err)
+		}
+	}
+	if first.calls != 2 {
+		t.Errorf("expected the open breaker to stop calls after 2 failures, got %d calls", first.calls)
+	}
+}
+
+func TestFallbackAgent_CanceledContextDoesNotFallBack(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	first := &stubAgent{complete: func(ctx context.Context, params ...string) (string, error) {
+		cancel()
+		return "", ctx.Err()
+	}}
+	second := &stubAgent{complete: func(ctx context.Context, params ...string) (string, error) {
+		return "unreachable", nil
+	}}
+	f := newStubFallback(first, second)
+
+	_, err := f.Completion(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if second.calls != 0 {
+		t.Error("expected no fallback once the caller's context is done")
+	}
+}
+
+func TestFallbackAgent_ForwardsPromptAndTools(t *testing.T) {
+	a, b := &stubAgent{}, &stubAgent{}
+	f := newStubFallback(a, b)
+
+	f.WithUserPrompt("hello")
+	f.WithTools(&mockTool{name: "search"})
+	f.AddCustomTools("weather", "desc", NewFunction(), func(string) (string, error) { return "", nil })
+
+	for i, s := range []*stubAgent{a, b} {
+		if s.prompt != "hello" {
+			t.Errorf("backend %d: expected prompt to be forwarded, got %q", i, s.prompt)
+		}
+		if len(s.tools) != 2 {
+			t.Errorf("backend %d: expected 2 tools, got %v", i, s.tools)
+		}
+	}
+}
+
+func TestFallbackAgent_AnthropicToGemini(t *testing.T) {
+	anthropicServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`))
+	}))
+	defer anthropicServer.Close()
+
+	geminiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := geminiResponse{
+			Candidates: []geminiCandidate{{Content: geminiContent{Role: "model", Parts: []geminiPart{{Text: "gemini says hi"}}}}},
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer geminiServer.Close()
+
+	agent := NewAgent().WithRole("r").WithBackstory("b").WithGoal("g")
+	f, err := NewFallbackAgent(
+		FallbackEntry{Agent: agent, Config: NewLLMConfig().
+			WithProvider(ProviderAnthropic).
+			WithModel(AnthropicModels.Claude4Sonnet).
+			WithAnthropicCredentials("key").
+			WithMaxRetries(1)},
+		FallbackEntry{Agent: agent, Config: NewLLMConfig().
+			WithProvider(ProviderGemini).
+			WithModel(GeminiModels.Gemini25Flash).
+			WithGeminiCredentials("key").
+			WithMaxRetries(1)},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f.backends[0].task.(*anthropicProvider).httpClient = &http.Client{
+		Transport: &testRewriteTransport{baseURL: anthropicServer.URL},
+	}
+	f.backends[1].task.(*geminiProvider).httpClient = &http.Client{
+		Transport: &testRewriteTransport{baseURL: geminiServer.URL},
+	}
+
+	f.WithUserPrompt("hello")
+	result, err := f.Completion(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "gemini says hi" {
+		t.Errorf("expected gemini answer, got %q", result)
+	}
+	if provider, _ := f.AnsweredBy(); provider != ProviderGemini {
+		t.Errorf("expected %q to answer, got %q", ProviderGemini, provider)
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"503", transientErr(), true},
+		{"400", &retryableError{err: ErrCompletionFailed, statusCode: http.StatusBadRequest}, false},
+		{"deadline", fmt.Errorf("%w: %w", ErrCompletionFailed, context.DeadlineExceeded), true},
+		{"plain", ErrMissingAPIKey, false},
+	}
+	for _, tt := range tests {
+		if got := isTransientError(tt.err); got != tt.want {
+			t.Errorf("%s: isTransientError() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
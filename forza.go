@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
+	"time"
 )
 
 // TaskChainFn is a function that takes a context and optional context strings and returns a result or error.
@@ -14,9 +16,53 @@ type TaskChainFn func(context.Context, ...string) (string, error)
 type TaskFn func(context.Context) (string, error)
 
 // Pipeline orchestrates the execution of multiple LLM tasks.
+//
+// When the context passed to a run carries a CostTracker, each task is given
+// a child tracker named "task N" so that costs roll up per task and per run.
 type Pipeline struct {
-	tasks  []TaskChainFn
-	logger *slog.Logger
+	tasks          []TaskChainFn
+	logger         *slog.Logger
+	maxConcurrency int
+	failFast       bool
+	blackboard     *blackboardRun
+}
+
+// TaskError is the failure of a single pipeline task. Index is the task's
+// position in the pipeline, starting at zero.
+type TaskError struct {
+	Index    int
+	Duration time.Duration
+	Err      error
+}
+
+func (e *TaskError) Error() string {
+	return fmt.Sprintf("task %d: %v", e.Index+1, e.Err)
+}
+
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
+
+// PipelineError collects the task errors of a RunConcurrently call, in task
+// order. errors.Is and errors.As look through every task error.
+type PipelineError struct {
+	Errors []*TaskError
+}
+
+func (e *PipelineError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e *PipelineError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
 }
 
 // NewPipeline creates a new empty Pipeline.
@@ -30,6 +76,36 @@ func (p *Pipeline) WithLogger(l *slog.Logger) *Pipeline {
 	return p
 }
 
+// WithMaxConcurrency limits how many tasks RunConcurrently runs at once.
+// Zero, the default, means no limit.
+func (p *Pipeline) WithMaxConcurrency(n int) *Pipeline {
+	p.maxConcurrency = n
+	return p
+}
+
+// WithFailFast makes RunConcurrently cancel the remaining tasks as soon as
+// one fails. Tasks that never start are reported with the context's error.
+func (p *Pipeline) WithFailFast(enabled bool) *Pipeline {
+	p.failFast = enabled
+	return p
+}
+
+// WithBlackboard shares b with the pipeline's tasks: runs and chains carry it
+// in their context, where MemoryTools and template chains find it. With the
+// WithMemoryTools option, every agent in the run also gets the memory tools.
+func (p *Pipeline) WithBlackboard(b *Blackboard, opts ...BlackboardOption) *Pipeline {
+	p.blackboard = newBlackboardRun(b, opts)
+	return p
+}
+
+// runContext attaches the pipeline's blackboard, if any, to ctx.
+func (p *Pipeline) runContext(ctx context.Context) context.Context {
+	if p.blackboard != nil {
+		return context.WithValue(ctx, blackboardKey{}, p.blackboard)
+	}
+	return ctx
+}
+
 func (p *Pipeline) logDebug(msg string, args ...any) {
 	if p.logger != nil {
 		p.logger.Debug(msg, args...)
@@ -46,6 +122,7 @@ func (p *Pipeline) AddTasks(fn ...TaskChainFn) {
 // the chain stops and the error is returned.
 func (p *Pipeline) CreateChain(tasks ...TaskChainFn) TaskFn {
 	return func(ctx context.Context) (string, error) {
+		ctx = p.runContext(ctx)
 		var result string
 		for i, task := range tasks {
 			if task == nil {
@@ -54,10 +131,11 @@ func (p *Pipeline) CreateChain(tasks ...TaskChainFn) TaskFn {
 			p.logDebug("chain progress", "task", i+1, "total", len(tasks))
 
 			var err error
+			taskCtx := taskContext(ctx, i)
 			if i == 0 {
-				result, err = task(ctx)
+				result, err = task(taskCtx)
 			} else {
-				result, err = task(ctx, result)
+				result, err = task(taskCtx, result)
 			}
 			if err != nil {
 				return "", fmt.Errorf("%w: task %d failed: %v", ErrChainInterrupted, i+1, err)
@@ -68,74 +146,103 @@ func (p *Pipeline) CreateChain(tasks ...TaskChainFn) TaskFn {
 }
 
 // RunConcurrently executes all added tasks concurrently and returns their results
-// in the original order. If any task fails or panics, its error is collected and
-// returned as a combined error after all tasks complete.
+// in the original order. At most WithMaxConcurrency tasks run at a time. If any
+// task fails or panics, its error is collected and returned as a *PipelineError
+// after all tasks complete; with WithFailFast, the first error also cancels the
+// context of the remaining tasks and prevents queued ones from starting.
 func (p *Pipeline) RunConcurrently(ctx context.Context) ([]string, error) {
-	var wg sync.WaitGroup
 	results := make([]string, len(p.tasks))
-	errs := make([]error, len(p.tasks))
+	taskErrs := make([]*TaskError, len(p.tasks))
+
+	runCtx, cancel := context.WithCancel(p.runContext(ctx))
+	defer cancel()
+	fail := func(index int, start time.Time, err error) {
+		taskErrs[index] = &TaskError{Index: index, Duration: time.Since(start), Err: err}
+		if p.failFast {
+			cancel()
+		}
+	}
 
-	type taskResult struct {
-		index  int
-		result string
-		err    error
+	var sem chan struct{}
+	if p.maxConcurrency > 0 {
+		sem = make(chan struct{}, p.maxConcurrency)
 	}
-	resultsChan := make(chan taskResult, len(p.tasks))
 
+	var wg sync.WaitGroup
 	for i, task := range p.tasks {
 		if task == nil {
-			errs[i] = fmt.Errorf("%w: task at index %d", ErrNilTask, i)
+			fail(i, time.Now(), fmt.Errorf("%w: task at index %d", ErrNilTask, i))
+			continue
+		}
+		if !acquire(runCtx, sem) {
+			taskErrs[i] = &TaskError{Index: i, Err: runCtx.Err()}
 			continue
 		}
 		wg.Add(1)
 		go func(index int, task TaskChainFn) {
 			defer wg.Done()
-			defer func() {
-				if r := recover(); r != nil {
-					resultsChan <- taskResult{
-						index: index,
-						err:   fmt.Errorf("task %d panicked: %v", index+1, r),
-					}
-				}
-			}()
+			if sem != nil {
+				defer func() { <-sem }()
+			}
+			start := time.Now()
 			p.logDebug("task started", "task", index+1)
 
-			result, err := task(ctx)
-
-			p.logDebug("task finished", "task", index+1)
+			result, err := runTask(taskContext(runCtx, index), task)
 
-			resultsChan <- taskResult{index: index, result: result, err: err}
+			p.logDebug("task finished", "task", index+1, "duration", time.Since(start))
+			results[index] = result
+			if err != nil {
+				fail(index, start, err)
+			}
 		}(i, task)
 	}
+	wg.Wait()
 
-	go func() {
-		wg.Wait()
-		close(resultsChan)
-	}()
-
-	for r := range resultsChan {
-		results[r.index] = r.result
-		errs[r.index] = r.err
+	var errs []*TaskError
+	for _, err := range taskErrs {
+		if err != nil {
+			errs = append(errs, err)
+		}
 	}
+	if len(errs) > 0 {
+		return results, &PipelineError{Errors: errs}
+	}
+	return results, nil
+}
 
-	// Collect errors
-	var combinedErr error
-	for i, err := range errs {
-		if err != nil {
-			if combinedErr == nil {
-				combinedErr = fmt.Errorf("task %d: %w", i+1, err)
-			} else {
-				combinedErr = fmt.Errorf("%v; task %d: %w", combinedErr, i+1, err)
-			}
+// acquire takes a slot from sem, which may be nil for no limit. It reports
+// false without holding a slot once ctx is done, even if a slot is free.
+func acquire(ctx context.Context, sem chan struct{}) bool {
+	if sem != nil {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	if ctx.Err() != nil {
+		if sem != nil {
+			<-sem
 		}
+		return false
 	}
+	return true
+}
 
-	return results, combinedErr
+// runTask calls task, turning a panic into an error.
+func runTask(ctx context.Context, task TaskChainFn) (result string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panicked: %v", r)
+		}
+	}()
+	return task(ctx)
 }
 
 // RunSequentially executes all added tasks one after another. Each task receives
 // no context arguments. If any task fails, execution stops and the error is returned.
 func (p *Pipeline) RunSequentially(ctx context.Context) ([]string, error) {
+	ctx = p.runContext(ctx)
 	results := make([]string, 0, len(p.tasks))
 	for i, task := range p.tasks {
 		if task == nil {
@@ -143,7 +250,7 @@ func (p *Pipeline) RunSequentially(ctx context.Context) ([]string, error) {
 		}
 		p.logDebug("sequential progress", "task", i+1, "total", len(p.tasks))
 
-		result, err := task(ctx)
+		result, err := task(taskContext(ctx, i))
 		if err != nil {
 			return results, fmt.Errorf("task %d failed: %w", i+1, err)
 		}
@@ -385,6 +385,91 @@ func TestRunConcurrently_Empty(t *testing.T) {
 	}
 }
 
+func TestRunConcurrently_MaxConcurrency(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	slow := func(ctx context.Context, params ...string) (string, error) {
+		n := running.Add(1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		running.Add(-1)
+		return "ok", nil
+	}
+
+	p := NewPipeline().WithMaxConcurrency(2)
+	p.AddTasks(slow, slow, slow, slow, slow)
+	results, err := p.RunConcurrently(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 5 || results[4] != "ok" {
+		t.Errorf("unexpected results: %v", results)
+	}
+	if maxRunning.Load() != 2 {
+		t.Errorf("expected at most 2 tasks at once, got %d", maxRunning.Load())
+	}
+}
+
+func TestRunConcurrently_FailFast(t *testing.T) {
+	var started atomic.Int32
+	blocking := func(ctx context.Context, params ...string) (string, error) {
+		started.Add(1)
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(5 * time.Second):
+			return "done", nil
+		}
+	}
+
+	p := NewPipeline().WithMaxConcurrency(2).WithFailFast(true)
+	p.AddTasks(blocking, mockErrorTask("boom"), blocking, blocking)
+
+	start := time.Now()
+	_, err := p.RunConcurrently(context.Background())
+	if time.Since(start) > time.Second {
+		t.Fatal("expected the failure to cancel the running task")
+	}
+	if started.Load() != 1 {
+		t.Errorf("expected queued tasks not to start, %d started", started.Load())
+	}
+
+	var pipelineErr *PipelineError
+	if !errors.As(err, &pipelineErr) || len(pipelineErr.Errors) != 4 {
+		t.Fatalf("expected a PipelineError for every task, got %v", err)
+	}
+	if first := pipelineErr.Errors[1]; first.Index != 1 || first.Err.Error() != "boom" {
+		t.Errorf("unexpected task error %+v", first)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the other tasks to report cancellation, got %v", err)
+	}
+}
+
+func TestRunConcurrently_TaskErrors(t *testing.T) {
+	p := NewPipeline()
+	p.AddTasks(mockTask("ok"), func(context.Context, ...string) (string, error) {
+		time.Sleep(10 * time.Millisecond)
+		return "", ErrBudgetExceeded
+	})
+
+	_, err := p.RunConcurrently(context.Background())
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("expected errors.Is to see the task error, got %v", err)
+	}
+	var taskErr *TaskError
+	if !errors.As(err, &taskErr) || taskErr.Index != 1 || taskErr.Duration < 10*time.Millisecond {
+		t.Errorf("expected a TaskError with index and duration, got %+v", taskErr)
+	}
+	if err.Error() != "task 2: "+ErrBudgetExceeded.Error() {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+}
+
 // --- RunSequentially tests ---
 
 func TestRunSequentially_MultipleTasks(t *testing.T) {
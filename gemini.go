@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
+	"slices"
 
 	"github.com/vitoraguila/forza/tools"
 )
@@ -16,9 +18,10 @@ const geminiAPIBaseURL = "https://generativelanguage.googleapis.com/v1beta/model
 type geminiProvider struct {
 	config        *LLMConfig
 	functions     []geminiFunctionDecl
-	fnExecutable  map[string]func(param string) (string, error)
+	fnExecutable  map[string]toolFunc
 	builtinTools  map[string]bool
 	systemPrompts []agentPrompts
+	memory        agentMemory
 	userPrompt    *string
 	httpClient    *http.Client
 }
@@ -69,8 +72,15 @@ type geminiGenerationConfig struct {
 }
 
 type geminiResponse struct {
-	Candidates []geminiCandidate `json:"candidates"`
-	Error      *geminiError      `json:"error,omitempty"`
+	Candidates    []geminiCandidate    `json:"candidates"`
+	UsageMetadata *geminiUsageMetadata `json:"usageMetadata,omitempty"`
+	Error         *geminiError         `json:"error,omitempty"`
+}
+
+type geminiUsageMetadata struct {
+	PromptTokenCount     int `json:"promptTokenCount"`
+	CandidatesTokenCount int `json:"candidatesTokenCount"`
+	TotalTokenCount      int `json:"totalTokenCount"`
 }
 
 type geminiCandidate struct {
@@ -86,13 +96,14 @@ type geminiError struct {
 // --- Constructor ---
 
 func newGemini(c *LLMConfig, a *Agent) LLMAgent {
-	fnExecutable := make(map[string]func(param string) (string, error))
+	fnExecutable := make(map[string]toolFunc)
 	builtinTools := make(map[string]bool)
 
 	return &geminiProvider{
 		config:        c,
 		fnExecutable:  fnExecutable,
 		systemPrompts: buildSystemPrompts(a),
+		memory:        a.agentMemory(),
 		builtinTools:  builtinTools,
 		httpClient:    &http.Client{Timeout: c.timeout},
 	}
@@ -115,11 +126,7 @@ func (g *geminiProvider) WithTools(t ...tools.Tool) {
 				"required": []string{"input"},
 			},
 		})
-		g.fnExecutable[tool.Name()] = func(t tools.Tool) func(string) (string, error) {
-			return func(input string) (string, error) {
-				return t.Call(context.Background(), input)
-			}
-		}(tool)
+		g.fnExecutable[tool.Name()] = tool.Call
 		g.builtinTools[tool.Name()] = true
 	}
 }
@@ -147,11 +154,35 @@ func (g *geminiProvider) AddCustomTools(name string, description string, params
 			"required":   required,
 		},
 	})
-	g.fnExecutable[name] = fn
+	g.fnExecutable[name] = customToolFunc(fn)
+}
+
+// withRunTools returns a copy of the provider for a single completion, with
+// extra tools added by the run.
+func (g *geminiProvider) withRunTools(extra []tools.Tool) *geminiProvider {
+	call := &geminiProvider{
+		config:        g.config,
+		functions:     slices.Clone(g.functions),
+		fnExecutable:  maps.Clone(g.fnExecutable),
+		builtinTools:  maps.Clone(g.builtinTools),
+		systemPrompts: g.systemPrompts,
+		memory:        g.memory,
+		userPrompt:    g.userPrompt,
+		httpClient:    g.httpClient,
+	}
+	call.WithTools(extra...)
+	return call
 }
 
 func (g *geminiProvider) Completion(ctx context.Context, params ...string) (string, error) {
-	userPrompt, err := resolveUserPrompt(g.userPrompt, params)
+	if extra := runTools(ctx, g.fnExecutable); len(extra) > 0 {
+		return g.withRunTools(extra).Completion(ctx, params...)
+	}
+	systemPrompts, err := g.memory.systemPrompts(ctx, g.systemPrompts, g.userPrompt, params)
+	if err != nil {
+		return "", err
+	}
+	userPrompt, err := fitUserPrompt(g.config, systemPrompts, g.userPrompt, params)
 	if err != nil {
 		return "", err
 	}
@@ -163,7 +194,7 @@ func (g *geminiProvider) Completion(ctx context.Context, params ...string) (stri
 
 	// Build system instruction
 	var systemText string
-	for _, p := range g.systemPrompts {
+	for _, p := range systemPrompts {
 		if systemText != "" {
 			systemText += "\n"
 		}
@@ -180,7 +211,7 @@ func (g *geminiProvider) Completion(ctx context.Context, params ...string) (stri
 		},
 		GenerationConfig: &geminiGenerationConfig{
 			Temperature: g.config.temperature,
-			MaxTokens:   g.config.maxTokens,
+			MaxTokens:   g.config.outputTokens(),
 		},
 	}
 
@@ -190,10 +221,30 @@ func (g *geminiProvider) Completion(ctx context.Context, params ...string) (stri
 		}
 	}
 
-	if len(g.functions) > 0 {
+	if len(g.functions) > 0 && g.config.modelInfo().SupportsTools {
 		req.Tools = []geminiToolDef{
 			{FunctionDeclarations: g.functions},
 		}
+	} else if len(g.functions) > 0 {
+		reactTools := make([]reactTool, len(g.functions))
+		for i, f := range g.functions {
+			reactTools[i] = reactTool{name: f.Name, description: f.Description, parameters: f.Parameters, builtin: g.builtinTools[f.Name], fn: g.fnExecutable[f.Name]}
+		}
+		return runReAct(ctx, userPrompt, reactTools, func(ctx context.Context, prompt string) (string, error) {
+			req.Contents = []geminiContent{{Role: "user", Parts: []geminiPart{{Text: prompt}}}}
+			resp, err := g.doRequest(ctx, apiKey, req)
+			if err != nil {
+				return "", err
+			}
+			if len(resp.Candidates) == 0 {
+				return "", fmt.Errorf("%w: no candidates returned", ErrCompletionFailed)
+			}
+			var text string
+			for _, part := range resp.Candidates[0].Content.Parts {
+				text += part.Text
+			}
+			return text, nil
+		})
 	}
 
 	resp, err := g.doRequest(ctx, apiKey, req)
@@ -247,7 +298,7 @@ func (g *geminiProvider) Completion(ctx context.Context, params ...string) (stri
 				toolInput = string(argsJSON)
 			}
 
-			content, err := fn(toolInput)
+			content, err := fn(ctx, toolInput)
 			if err != nil {
 				return "", fmt.Errorf("%w: tool %q: %v", ErrToolCallFailed, fc.Name, err)
 			}
@@ -269,7 +320,7 @@ func (g *geminiProvider) Completion(ctx context.Context, params ...string) (stri
 
 		resp, err = g.doRequest(ctx, apiKey, req)
 		if err != nil {
-			return "", fmt.Errorf("%w: follow-up after tool call: %v", ErrCompletionFailed, err)
+			return "", fmt.Errorf("%w: follow-up after tool call: %w", ErrCompletionFailed, err)
 		}
 
 		if len(resp.Candidates) == 0 {
@@ -296,11 +347,23 @@ func (g *geminiProvider) doRequest(ctx context.Context, apiKey string, reqBody g
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("x-goog-api-key", apiKey)
 
+	if err := checkBudget(ctx); err != nil {
+		return nil, err
+	}
+
 	var geminiResp geminiResponse
+	var usage Usage
 	doFn := func() error {
+		res, err := reserveRate(ctx, g.config, estimateTokens(string(body))+g.config.outputTokens())
+		if err != nil {
+			return err
+		}
+		usage = Usage{}
+		defer func() { res.reconcile(usage.TotalTokens()) }()
+
 		resp, err := g.httpClient.Do(req)
 		if err != nil {
-			return fmt.Errorf("%w: request failed: %v", ErrCompletionFailed, err)
+			return fmt.Errorf("%w: request failed: %w", ErrCompletionFailed, err)
 		}
 		defer resp.Body.Close()
 
@@ -329,6 +392,13 @@ func (g *geminiProvider) doRequest(ctx context.Context, apiKey string, reqBody g
 			return fmt.Errorf("%w: failed to parse response: %v", ErrCompletionFailed, err)
 		}
 
+		if geminiResp.UsageMetadata != nil {
+			usage = Usage{
+				PromptTokens:     geminiResp.UsageMetadata.PromptTokenCount,
+				CompletionTokens: geminiResp.UsageMetadata.TotalTokenCount - geminiResp.UsageMetadata.PromptTokenCount,
+			}
+		}
+
 		if geminiResp.Error != nil {
 			return fmt.Errorf("%w: API error [%s]: %s", ErrCompletionFailed, geminiResp.Error.Status, geminiResp.Error.Message)
 		}
@@ -339,6 +409,7 @@ func (g *geminiProvider) doRequest(ctx context.Context, apiKey string, reqBody g
 	if err := withRetry(ctx, g.config.maxRetries, doFn); err != nil {
 		return nil, err
 	}
+	recordUsage(ctx, g.config.model, usage)
 
 	return &geminiResp, nil
 }
@@ -44,6 +44,10 @@ type LLMConfig struct {
 	maxTokens   int
 	timeout     time.Duration
 	maxRetries  int
+	rateLimit   RateLimit
+	contextFit  ContextFit
+
+	discoveryTTL time.Duration
 }
 
 // NewLLMConfig creates a new LLMConfig with sensible defaults.
@@ -70,9 +74,25 @@ func (c *LLMConfig) Validate() error {
 	if c.maxTokens <= 0 {
 		return fmt.Errorf("%w: maxTokens must be greater than 0, got %d", ErrInvalidConfig, c.maxTokens)
 	}
+	if c.rateLimit.RequestsPerMinute < 0 || c.rateLimit.TokensPerMinute < 0 {
+		return fmt.Errorf("%w: rate limits must not be negative", ErrInvalidConfig)
+	}
+	if c.discoveryTTL < 0 {
+		return fmt.Errorf("%w: model discovery TTL must not be negative", ErrInvalidConfig)
+	}
 	return nil
 }
 
+// Provider returns the configured provider.
+func (c *LLMConfig) Provider() string {
+	return c.provider
+}
+
+// Model returns the configured model identifier.
+func (c *LLMConfig) Model() string {
+	return c.model
+}
+
 // WithTemperature sets the sampling temperature (0.0 - 2.0).
 func (c *LLMConfig) WithTemperature(temperature float64) *LLMConfig {
 	c.temperature = temperature
@@ -109,6 +129,33 @@ func (c *LLMConfig) WithMaxRetries(n int) *LLMConfig {
 	return c
 }
 
+// WithRateLimit enables client-side rate limiting. The budget is shared by
+// every task using the same provider and API key. Pass 0 to leave a
+// dimension unlimited.
+func (c *LLMConfig) WithRateLimit(requestsPerMinute, tokensPerMinute int) *LLMConfig {
+	c.rateLimit = RateLimit{
+		RequestsPerMinute: requestsPerMinute,
+		TokensPerMinute:   tokensPerMinute,
+	}
+	return c
+}
+
+// WithContextFit sets how an oversized user prompt context is handled before
+// the request is sent (default: ContextFitNone).
+func (c *LLMConfig) WithContextFit(strategy ContextFit) *LLMConfig {
+	c.contextFit = strategy
+	return c
+}
+
+// WithModelDiscovery makes NewLLMTask validate the model against the list the
+// provider reports for these credentials (see ListModels) instead of the
+// built-in catalog. Lists are cached per provider and credentials for ttl.
+// Pass 0 to disable.
+func (c *LLMConfig) WithModelDiscovery(ttl time.Duration) *LLMConfig {
+	c.discoveryTTL = ttl
+	return c
+}
+
 // WithOpenAiCredentials sets OpenAI API credentials.
 func (c *LLMConfig) WithOpenAiCredentials(openAiApiKey string) *LLMConfig {
 	c.credentials = credentials{
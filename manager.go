@@ -0,0 +1,311 @@
+package forza
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/vitoraguila/forza/tools"
+)
+
+const (
+	defaultManagerMaxDepth       = 2
+	defaultManagerMaxDelegations = 10
+
+	delegateToolPrefix = "delegate_to_"
+)
+
+// Worker is a member of a Manager's roster. It is either an agent with its
+// configuration and tools, or a sub-team led by another Manager.
+type Worker struct {
+	// Name identifies the worker in tool names and the transcript. It must
+	// contain only letters, digits and underscores.
+	Name    string
+	Agent   *Agent
+	Config  *LLMConfig
+	Tools   []tools.Tool
+	Manager *Manager
+}
+
+// Delegation is one entry of a Manager's transcript: a subtask assigned to a
+// worker and its outcome.
+type Delegation struct {
+	Worker   string        `json:"worker"`
+	Task     string        `json:"task"`
+	Result   string        `json:"result,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Depth    int           `json:"depth"`
+	Duration time.Duration `json:"duration"`
+}
+
+// ManagerResult is the manager's final answer and the delegations that led
+// to it, in the order they finished. Delegations made by sub-managers are
+// included with a greater Depth.
+type ManagerResult struct {
+	Answer     string
+	Transcript []Delegation
+}
+
+// Manager is a hierarchical agent: given a goal, its agent plans subtasks,
+// delegates them to a roster of workers and synthesizes their results into
+// a final answer. Delegation runs through the provider's tool-calling loop,
+// with one "delegate_to_<name>" tool per worker; models without native
+// function calling use the ReAct text loop.
+//
+// Each Run creates fresh agents, so a Manager can serve concurrent runs.
+// When the context carries a CostTracker, each delegation is tracked under a
+// child named after the worker.
+type Manager struct {
+	agent          *Agent
+	config         *LLMConfig
+	workers        []Worker
+	maxDepth       int
+	maxDelegations int
+	logger         *slog.Logger
+}
+
+// NewManager creates a Manager whose planning and synthesis run as agent
+// with config.
+func NewManager(agent *Agent, config *LLMConfig) *Manager {
+	return &Manager{
+		agent:          agent,
+		config:         config,
+		maxDepth:       defaultManagerMaxDepth,
+		maxDelegations: defaultManagerMaxDelegations,
+	}
+}
+
+// WithWorkers adds workers to the roster.
+func (m *Manager) WithWorkers(workers ...Worker) *Manager {
+	m.workers = append(m.workers, workers...)
+	return m
+}
+
+// WithMaxDepth limits how deep delegation may nest: 1 lets only this
+// manager delegate, 2 (the default) also lets sub-managers delegate.
+// Beyond the limit a sub-manager must answer on its own.
+func (m *Manager) WithMaxDepth(n int) *Manager {
+	m.maxDepth = n
+	return m
+}
+
+// WithMaxDelegations limits the delegations in one run, sub-managers
+// included. Default is 10. Once reached, the delegate tools tell the model to
+// answer with what it has.
+func (m *Manager) WithMaxDelegations(n int) *Manager {
+	m.maxDelegations = n
+	return m
+}
+
+// WithLogger sets an optional logger. If nil, no logging occurs.
+func (m *Manager) WithLogger(l *slog.Logger) *Manager {
+	m.logger = l
+	return m
+}
+
+// Task returns the manager as a TaskChainFn for use in a Pipeline or DAG.
+// Its context argument is the goal.
+func (m *Manager) Task() TaskChainFn {
+	return func(ctx context.Context, params ...string) (string, error) {
+		if len(params) != 1 {
+			return "", fmt.Errorf("%w: a manager needs the goal as its only context argument", ErrTooManyArgs)
+		}
+		result, err := m.Run(ctx, params[0])
+		return result.Answer, err
+	}
+}
+
+// managerRun is the state shared by a top-level run and its sub-managers.
+type managerRun struct {
+	mu          sync.Mutex
+	transcript  []Delegation
+	delegations int
+}
+
+type managerRunKey struct{}
+
+// managerState carries the current run and delegation depth in a context,
+// so that sub-managers reached through tool calls share the transcript and
+// limits of the top-level run.
+type managerState struct {
+	run      *managerRun
+	depth    int
+	maxDepth int
+	maxTotal int
+}
+
+// Run plans, delegates and synthesizes an answer for goal. The transcript is
+// returned even when the run fails.
+func (m *Manager) Run(ctx context.Context, goal string) (ManagerResult, error) {
+	state, nested := ctx.Value(managerRunKey{}).(*managerState)
+	if !nested {
+		state = &managerState{run: &managerRun{}, maxDepth: m.maxDepth, maxTotal: m.maxDelegations}
+		ctx = context.WithValue(ctx, managerRunKey{}, state)
+	}
+	result := func(answer string) ManagerResult {
+		if nested {
+			return ManagerResult{Answer: answer}
+		}
+		state.run.mu.Lock()
+		defer state.run.mu.Unlock()
+		return ManagerResult{Answer: answer, Transcript: append([]Delegation(nil), state.run.transcript...)}
+	}
+
+	if err := m.validate(); err != nil {
+		return result(""), err
+	}
+	task, err := m.agent.NewLLMTask(m.config)
+	if err != nil {
+		return result(""), fmt.Errorf("manager: %w", err)
+	}
+
+	canDelegate := state.depth < state.maxDepth
+	if canDelegate {
+		delegates := make([]tools.Tool, len(m.workers))
+		for i := range m.workers {
+			delegates[i] = &delegateTool{manager: m, worker: &m.workers[i]}
+		}
+		task.WithTools(delegates...)
+	}
+	task.WithUserPrompt(m.prompt(goal, canDelegate))
+
+	answer, err := task.Completion(ctx)
+	if err != nil {
+		return result(""), err
+	}
+	return result(answer), nil
+}
+
+// prompt builds the manager's planning instructions.
+func (m *Manager) prompt(goal string, canDelegate bool) string {
+	if !canDelegate {
+		return "Accomplish the following goal yourself and answer with the result.\n\nGoal: " + goal
+	}
+	return "You lead a team. Accomplish the following goal by breaking it into subtasks and assigning each one to the best-suited team member " +
+		"with the " + delegateToolPrefix + "* tools. Team members cannot see the goal or each other's work, so give each a complete, self-contained " +
+		"description of their subtask and include any earlier results they need. Independent subtasks can be assigned together. " +
+		"When you have what you need, combine the results into the final answer yourself.\n\nGoal: " + goal
+}
+
+func (m *Manager) validate() error {
+	if m.agent == nil || m.config == nil {
+		return fmt.Errorf("%w: a manager needs an Agent and a Config", ErrInvalidConfig)
+	}
+	if len(m.workers) == 0 {
+		return fmt.Errorf("%w: a manager needs at least one worker", ErrInvalidConfig)
+	}
+	seen := make(map[string]bool, len(m.workers))
+	for i, w := range m.workers {
+		if !templateNamePattern.MatchString(w.Name) {
+			return fmt.Errorf("%w: worker %d: name %q must contain only letters, digits and underscores", ErrInvalidConfig, i+1, w.Name)
+		}
+		if seen[w.Name] {
+			return fmt.Errorf("%w: duplicate worker %q", ErrInvalidConfig, w.Name)
+		}
+		seen[w.Name] = true
+		if w.Manager == m {
+			return fmt.Errorf("%w: worker %q is the manager itself", ErrInvalidConfig, w.Name)
+		}
+		if w.Manager == nil {
+			if w.Agent == nil || w.Config == nil {
+				return fmt.Errorf("%w: worker %q needs an Agent and a Config, or a Manager", ErrInvalidConfig, w.Name)
+			}
+			if _, err := w.Agent.NewLLMTask(w.Config); err != nil {
+				return fmt.Errorf("worker %q: %w", w.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// delegateTool assigns a subtask to one worker.
+type delegateTool struct {
+	manager *Manager
+	worker  *Worker
+}
+
+func (d *delegateTool) Name() string {
+	return delegateToolPrefix + d.worker.Name
+}
+
+func (d *delegateTool) Description() string {
+	a := d.worker.Agent
+	if d.worker.Manager != nil {
+		a = d.worker.Manager.agent
+	}
+	desc := "Assign a subtask to " + d.worker.Name
+	if a != nil {
+		desc += fmt.Sprintf(", a %s whose goal is %s", a.Role, a.Goal)
+	}
+	if d.worker.Manager != nil {
+		desc += ", who leads their own team"
+	}
+	return desc + ". The input is a complete, self-contained description of the subtask; the result is their answer."
+}
+
+// Call runs the worker. Worker failures are reported to the model as the
+// tool result so it can adapt; cancellation and budget exhaustion end the
+// run instead.
+func (d *delegateTool) Call(ctx context.Context, input string) (string, error) {
+	state, ok := ctx.Value(managerRunKey{}).(*managerState)
+	if !ok {
+		return "", fmt.Errorf("%w: delegate tool called outside a manager run", ErrInvalidConfig)
+	}
+
+	state.run.mu.Lock()
+	if state.run.delegations >= state.maxTotal {
+		state.run.mu.Unlock()
+		return "Delegation limit reached. Do not delegate further; answer with the results you already have.", nil
+	}
+	state.run.delegations++
+	state.run.mu.Unlock()
+
+	depth := state.depth + 1
+	d.manager.logDebug("delegating", "worker", d.worker.Name, "depth", depth)
+	start := time.Now()
+	result, err := d.run(namedTaskContext(ctx, d.worker.Name), state, depth, input)
+
+	entry := Delegation{Worker: d.worker.Name, Task: input, Result: result, Depth: depth, Duration: time.Since(start)}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	state.run.mu.Lock()
+	state.run.transcript = append(state.run.transcript, entry)
+	state.run.mu.Unlock()
+
+	if err != nil {
+		if ctx.Err() != nil || errors.Is(err, ErrBudgetExceeded) {
+			return "", err
+		}
+		return fmt.Sprintf("%s failed: %v", d.worker.Name, err), nil
+	}
+	return result, nil
+}
+
+func (d *delegateTool) run(ctx context.Context, state *managerState, depth int, input string) (string, error) {
+	if sub := d.worker.Manager; sub != nil {
+		child := *state
+		child.depth = depth
+		result, err := sub.Run(context.WithValue(ctx, managerRunKey{}, &child), input)
+		return result.Answer, err
+	}
+
+	task, err := d.worker.Agent.NewLLMTask(d.worker.Config)
+	if err != nil {
+		return "", err
+	}
+	if len(d.worker.Tools) > 0 {
+		task.WithTools(d.worker.Tools...)
+	}
+	task.WithUserPrompt(input)
+	return task.Completion(ctx)
+}
+
+func (m *Manager) logDebug(msg string, args ...any) {
+	if m.logger != nil {
+		m.logger.Debug(msg, args...)
+	}
+}
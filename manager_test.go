@@ -0,0 +1,208 @@
+package forza
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// teamServer scripts an OpenAI-compatible backend. A request offering
+// delegate tools gets one delegation per tool on its first round and a
+// summary of the tool results afterwards; any other request is answered by a
+// worker echoing its prompt.
+func teamServer(t *testing.T) (*httptest.Server, *[]openai.ChatCompletionRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []openai.ChatCompletionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openai.ChatCompletionRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+
+		last := req.Messages[len(req.Messages)-1]
+		var msg openai.ChatCompletionMessage
+		switch {
+		case len(req.Tools) > 0 && last.Role == openai.ChatMessageRoleUser:
+			for _, tool := range req.Tools {
+				args, _ := json.Marshal(map[string]string{"input": "subtask for " + tool.Function.Name})
+				msg.ToolCalls = append(msg.ToolCalls, openai.ToolCall{
+					ID:       "call_" + tool.Function.Name,
+					Type:     openai.ToolTypeFunction,
+					Function: openai.FunctionCall{Name: tool.Function.Name, Arguments: string(args)},
+				})
+			}
+		case last.Role == openai.ChatMessageRoleTool:
+			var results []string
+			for _, m := range req.Messages {
+				if m.Role == openai.ChatMessageRoleTool {
+					results = append(results, m.Content)
+				}
+			}
+			msg.Content = "synthesized: " + strings.Join(results, " | ")
+		default:
+			msg.Content = "done: " + last.Content
+		}
+		json.NewEncoder(w).Encode(openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{Message: msg}}})
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func teamConfig(serverURL string) *LLMConfig {
+	return NewLLMConfig().WithProvider(ProviderOllama).WithModel(OllamaModels.Llama31).WithOllamaCredentials(serverURL + "/v1")
+}
+
+func teamAgent(role string) *Agent {
+	return NewAgent().WithRole(role).WithBackstory("backstory").WithGoal(role + " work")
+}
+
+func TestManager_DelegatesAndSynthesizes(t *testing.T) {
+	server, requests := teamServer(t)
+	config := teamConfig(server.URL)
+	tracker := NewCostTracker()
+
+	result, err := NewManager(teamAgent("lead"), config).
+		WithWorkers(
+			Worker{Name: "researcher", Agent: teamAgent("researcher"), Config: config},
+			Worker{Name: "writer", Agent: teamAgent("writer"), Config: config},
+		).
+		Run(ContextWithCostTracker(context.Background(), tracker), "write a report")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "synthesized: done: subtask for delegate_to_researcher | done: subtask for delegate_to_writer"
+	if result.Answer != want {
+		t.Errorf("unexpected answer %q", result.Answer)
+	}
+	if len(result.Transcript) != 2 || result.Transcript[0].Worker != "researcher" || result.Transcript[1].Depth != 1 ||
+		result.Transcript[0].Result != "done: subtask for delegate_to_researcher" {
+		t.Errorf("unexpected transcript %+v", result.Transcript)
+	}
+	if !strings.Contains((*requests)[0].Messages[2].Content, "Goal: write a report") {
+		t.Errorf("expected the goal in the manager prompt, got %q", (*requests)[0].Messages[2].Content)
+	}
+	if desc := (*requests)[0].Tools[0].Function.Description; !strings.Contains(desc, "a researcher whose goal is researcher work") {
+		t.Errorf("expected the worker's role in the tool description, got %q", desc)
+	}
+
+	var names []string
+	for _, c := range tracker.Children() {
+		names = append(names, c.Name())
+	}
+	if strings.Join(names, ",") != "researcher,writer" {
+		t.Errorf("expected a cost child per delegation, got %v", names)
+	}
+}
+
+func TestManager_DepthAndDelegationLimits(t *testing.T) {
+	server, _ := teamServer(t)
+	config := teamConfig(server.URL)
+	subTeam := NewManager(teamAgent("editor in chief"), config).
+		WithWorkers(Worker{Name: "copyeditor", Agent: teamAgent("copyeditor"), Config: config})
+
+	// With depth 1 the sub-manager must answer on its own.
+	result, err := NewManager(teamAgent("lead"), config).
+		WithWorkers(Worker{Name: "editors", Manager: subTeam}).
+		WithMaxDepth(1).
+		Run(context.Background(), "publish")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Transcript) != 1 || result.Transcript[0].Result != "done: Accomplish the following goal yourself and answer with the result.\n\nGoal: subtask for delegate_to_editors" {
+		t.Errorf("expected the sub-manager to work alone, got %+v", result.Transcript)
+	}
+
+	// With the default depth the sub-manager delegates too.
+	result, err = NewManager(teamAgent("lead"), config).
+		WithWorkers(Worker{Name: "editors", Manager: subTeam}).
+		Run(context.Background(), "publish")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Transcript) != 2 || result.Transcript[0].Worker != "copyeditor" || result.Transcript[0].Depth != 2 {
+		t.Errorf("expected the nested delegation in the transcript, got %+v", result.Transcript)
+	}
+
+	result, err = NewManager(teamAgent("lead"), config).
+		WithWorkers(
+			Worker{Name: "a", Agent: teamAgent("a"), Config: config},
+			Worker{Name: "b", Agent: teamAgent("b"), Config: config},
+		).
+		WithMaxDelegations(1).
+		Run(context.Background(), "x")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Transcript) != 1 || !strings.Contains(result.Answer, "Delegation limit reached") {
+		t.Errorf("expected the second delegation to be refused, got %+v", result)
+	}
+}
+
+func TestManager_Validate(t *testing.T) {
+	config := teamConfig("http://localhost")
+	tests := []struct {
+		name    string
+		workers []Worker
+		want    error
+	}{
+		{"no workers", nil, ErrInvalidConfig},
+		{"bad name", []Worker{{Name: "a b", Agent: teamAgent("a"), Config: config}}, ErrInvalidConfig},
+		{"duplicate", []Worker{{Name: "a", Agent: teamAgent("a"), Config: config}, {Name: "a", Agent: teamAgent("b"), Config: config}}, ErrInvalidConfig},
+		{"missing agent", []Worker{{Name: "a", Config: config}}, ErrInvalidConfig},
+		{"invalid agent", []Worker{{Name: "a", Agent: NewAgent(), Config: config}}, ErrMissingRole},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewManager(teamAgent("lead"), config).WithWorkers(tt.workers...).Run(context.Background(), "x")
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	delegate := &delegateTool{manager: NewManager(teamAgent("lead"), config), worker: &Worker{Name: "a"}}
+	if _, err := delegate.Call(context.Background(), "x"); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig outside a manager run, got %v", err)
+	}
+}
+
+func TestToolsReceiveCompletionContext(t *testing.T) {
+	server, _ := teamServer(t)
+	type key struct{}
+	var got any
+	task, _ := teamAgent("lead").NewLLMTask(teamConfig(server.URL))
+	task.WithTools(&funcTool{name: "probe", fn: func(ctx context.Context, input string) (string, error) {
+		got = ctx.Value(key{})
+		return "ok", nil
+	}})
+	task.WithUserPrompt("go")
+
+	if _, err := task.Completion(context.WithValue(context.Background(), key{}, "value")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "value" {
+		t.Errorf("expected the completion's context in the tool call, got %v", got)
+	}
+}
+
+// funcTool is a tools.Tool backed by a function.
+type funcTool struct {
+	name string
+	fn   func(ctx context.Context, input string) (string, error)
+}
+
+func (f *funcTool) Name() string        { return f.name }
+func (f *funcTool) Description() string { return "test tool" }
+func (f *funcTool) Call(ctx context.Context, input string) (string, error) {
+	return f.fn(ctx, input)
+}
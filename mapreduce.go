@@ -0,0 +1,262 @@
+package forza
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/vitoraguila/forza/tokenizer"
+)
+
+const (
+	defaultChunkTokens = 4000
+	maxReduceLevels    = 5
+
+	// partialSeparator separates partial results in a reduce prompt's context.
+	partialSeparator = "\n\n---\n\n"
+
+	defaultMapPrompt    = "Summarize the following part of a larger document. Keep every fact, figure and name that may matter for an overall summary."
+	defaultReducePrompt = "Combine the following partial summaries, separated by ---, into a single coherent summary. Remove repetition but keep every important fact."
+)
+
+// MapReduceResult is the outcome of a MapReduce run.
+type MapReduceResult struct {
+	Output string
+	// Chunks is the number of chunks the input was split into.
+	Chunks int
+	// Failed lists the indexes of chunks whose map step failed and were
+	// left out of the result.
+	Failed []int
+	// Levels is the number of reduce rounds; zero when a single chunk's map
+	// result was the output.
+	Levels int
+}
+
+// MapReduce processes inputs too large for one prompt. It splits the input
+// into chunks of at most the configured number of tokens, maps the agent
+// over the chunks concurrently, and then reduces the partial results: while
+// they don't fit in one chunk, they are grouped and reduced concurrently,
+// level by level, until a single reduce call produces the output.
+//
+// The map and reduce phases set the agent's user prompt in turn, so the
+// agent should be dedicated to the MapReduce. When the context carries a
+// CostTracker, the phases are tracked under children named "map" and
+// "reduce N".
+type MapReduce struct {
+	agent          LLMAgent
+	tokenizer      tokenizer.Tokenizer
+	chunkTokens    int
+	mapPrompt      string
+	reducePrompt   string
+	maxConcurrency int
+	maxFailures    int
+	logger         *slog.Logger
+}
+
+// NewMapReduce creates a MapReduce that runs agent and counts tokens with the
+// tokenizer of model, which should be the agent's model.
+func NewMapReduce(agent LLMAgent, model string) *MapReduce {
+	return &MapReduce{
+		agent:        agent,
+		tokenizer:    tokenizer.ForModel(model),
+		chunkTokens:  defaultChunkTokens,
+		mapPrompt:    defaultMapPrompt,
+		reducePrompt: defaultReducePrompt,
+	}
+}
+
+// WithChunkTokens sets the maximum size of a chunk, and of the combined
+// partial results passed to one reduce call. Default is 4000 tokens.
+func (m *MapReduce) WithChunkTokens(n int) *MapReduce {
+	m.chunkTokens = n
+	return m
+}
+
+// WithMapPrompt sets the user prompt for the map step; each chunk is passed
+// as its context.
+func (m *MapReduce) WithMapPrompt(prompt string) *MapReduce {
+	m.mapPrompt = prompt
+	return m
+}
+
+// WithReducePrompt sets the user prompt for the reduce step; the partial
+// results, separated by "---" lines, are passed as its context.
+func (m *MapReduce) WithReducePrompt(prompt string) *MapReduce {
+	m.reducePrompt = prompt
+	return m
+}
+
+// WithMaxConcurrency limits how many completions run at once. Zero, the
+// default, means no limit.
+func (m *MapReduce) WithMaxConcurrency(n int) *MapReduce {
+	m.maxConcurrency = n
+	return m
+}
+
+// WithMaxFailures lets up to n chunks fail in the map step; their content is
+// left out and their indexes reported in MapReduceResult.Failed. By default
+// any failure fails the run. Reduce failures always do.
+func (m *MapReduce) WithMaxFailures(n int) *MapReduce {
+	m.maxFailures = n
+	return m
+}
+
+// WithLogger sets an optional logger. If nil, no logging occurs.
+func (m *MapReduce) WithLogger(l *slog.Logger) *MapReduce {
+	m.logger = l
+	return m
+}
+
+// Task returns the MapReduce as a TaskChainFn whose context argument is the
+// input, for use in a Pipeline or DAG.
+func (m *MapReduce) Task() TaskChainFn {
+	return func(ctx context.Context, params ...string) (string, error) {
+		if len(params) != 1 {
+			return "", fmt.Errorf("%w: map-reduce needs the input as its only context argument", ErrTooManyArgs)
+		}
+		result, err := m.Run(ctx, params[0])
+		return result.Output, err
+	}
+}
+
+// Run splits, maps and reduces input.
+func (m *MapReduce) Run(ctx context.Context, input string) (MapReduceResult, error) {
+	var result MapReduceResult
+	if m.agent == nil {
+		return result, fmt.Errorf("%w: map-reduce has no agent", ErrNilTask)
+	}
+	if m.chunkTokens < 1 {
+		return result, fmt.Errorf("%w: chunk size must be at least 1 token", ErrInvalidConfig)
+	}
+
+	chunks := splitTokens(m.tokenizer, input, m.chunkTokens)
+	result.Chunks = len(chunks)
+	if len(chunks) == 0 {
+		return result, nil
+	}
+
+	m.agent.WithUserPrompt(m.mapPrompt)
+	m.logDebug("map started", "chunks", len(chunks))
+	partials, err := m.runAll(namedTaskContext(ctx, "map"), chunks)
+	var pipelineErr *PipelineError
+	if errors.As(err, &pipelineErr) && len(pipelineErr.Errors) <= m.maxFailures && len(pipelineErr.Errors) < len(chunks) {
+		for _, taskErr := range pipelineErr.Errors {
+			result.Failed = append(result.Failed, taskErr.Index)
+			partials[taskErr.Index] = ""
+		}
+		err = nil
+	}
+	if err != nil {
+		return result, fmt.Errorf("%w: map: %w", ErrMapReduceFailed, err)
+	}
+	partials = nonEmpty(partials)
+
+	m.agent.WithUserPrompt(m.reducePrompt)
+	for len(partials) > 1 {
+		if result.Levels == maxReduceLevels {
+			return result, fmt.Errorf("%w: %d partial results left after %d reduce levels", ErrMapReduceFailed, len(partials), maxReduceLevels)
+		}
+		result.Levels++
+		groups := groupTokens(m.tokenizer, partials, m.chunkTokens)
+		m.logDebug("reduce level", "level", result.Levels, "partials", len(partials), "groups", len(groups))
+		partials, err = m.runAll(namedTaskContext(ctx, fmt.Sprintf("reduce %d", result.Levels)), groups)
+		if err != nil {
+			return result, fmt.Errorf("%w: reduce level %d: %w", ErrMapReduceFailed, result.Levels, err)
+		}
+	}
+	if len(partials) == 1 {
+		result.Output = partials[0]
+	}
+	return result, nil
+}
+
+// runAll runs the agent on each input concurrently through a Pipeline.
+func (m *MapReduce) runAll(ctx context.Context, inputs []string) ([]string, error) {
+	p := NewPipeline().WithLogger(m.logger).WithMaxConcurrency(m.maxConcurrency)
+	for _, in := range inputs {
+		p.AddTasks(withDefaultContext(m.agent.Completion, in))
+	}
+	return p.RunConcurrently(ctx)
+}
+
+func (m *MapReduce) logDebug(msg string, args ...any) {
+	if m.logger != nil {
+		m.logger.Debug(msg, args...)
+	}
+}
+
+// splitTokens splits text into chunks of at most maxTokens, breaking between
+// paragraphs where possible and inside a paragraph only when it is too long
+// on its own.
+func splitTokens(tok tokenizer.Tokenizer, text string, maxTokens int) []string {
+	var chunks []string
+	var current strings.Builder
+	flush := func() {
+		if s := strings.TrimSpace(current.String()); s != "" {
+			chunks = append(chunks, s)
+		}
+		current.Reset()
+	}
+
+	for _, para := range strings.Split(text, "\n\n") {
+		if strings.TrimSpace(para) == "" {
+			continue
+		}
+		candidate := para
+		if current.Len() > 0 {
+			candidate = current.String() + "\n\n" + para
+		}
+		if tok.Count(candidate) <= maxTokens {
+			current.Reset()
+			current.WriteString(candidate)
+			continue
+		}
+		flush()
+		for tok.Count(para) > maxTokens {
+			head := tok.Head(para, maxTokens)
+			if head == "" {
+				break
+			}
+			chunks = append(chunks, strings.TrimSpace(head))
+			para = para[len(head):]
+		}
+		current.WriteString(para)
+	}
+	flush()
+	return chunks
+}
+
+// groupTokens packs partial results, in order, into as few groups of at most
+// maxTokens as possible. A partial larger than maxTokens forms its own group.
+func groupTokens(tok tokenizer.Tokenizer, partials []string, maxTokens int) []string {
+	var groups []string
+	current := ""
+	for _, p := range partials {
+		if current == "" {
+			current = p
+			continue
+		}
+		if candidate := current + partialSeparator + p; tok.Count(candidate) <= maxTokens {
+			current = candidate
+			continue
+		}
+		groups = append(groups, current)
+		current = p
+	}
+	if current != "" {
+		groups = append(groups, current)
+	}
+	return groups
+}
+
+func nonEmpty(values []string) []string {
+	out := values[:0]
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,135 @@
+package forza
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/vitoraguila/forza/tokenizer"
+)
+
+// mapReduceServer answers "MAP" prompts with a short summary of the chunk's
+// first word and "REDUCE" prompts with the number of partials combined. Chunks
+// containing "BAD" fail.
+func mapReduceServer(t *testing.T, reduces *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openai.ChatCompletionRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		prompt, input, _ := strings.Cut(req.Messages[len(req.Messages)-1].Content, contextSeparator)
+
+		var answer string
+		switch {
+		case strings.Contains(input, "BAD"):
+			http.Error(w, `{"error":{"message":"bad chunk"}}`, http.StatusBadRequest)
+			return
+		case prompt == "MAP":
+			answer = "summary of " + strings.Fields(input)[0]
+		case prompt == "REDUCE":
+			reduces.Add(1)
+			answer = fmt.Sprintf("combined %d", strings.Count(input, partialSeparator)+1)
+		}
+		json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: answer}}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func paragraphs(n int) string {
+	var parts []string
+	for i := 0; i < n; i++ {
+		parts = append(parts, fmt.Sprintf("p%d %s", i, strings.Repeat("lorem ipsum ", 20)))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func TestMapReduce_Run(t *testing.T) {
+	var reduces atomic.Int32
+	server := mapReduceServer(t, &reduces)
+
+	result, err := NewMapReduce(newTestOllamaTask(server.URL), OllamaModels.Llama31).
+		WithChunkTokens(200).
+		WithMapPrompt("MAP").
+		WithReducePrompt("REDUCE").
+		Run(context.Background(), paragraphs(12))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Chunks < 2 || result.Levels != 1 || reduces.Load() != 1 {
+		t.Errorf("expected several chunks and one reduce call, got %+v after %d reduces", result, reduces.Load())
+	}
+	if result.Output != fmt.Sprintf("combined %d", result.Chunks) {
+		t.Errorf("expected every chunk in the reduce, got %q", result.Output)
+	}
+}
+
+func TestMapReduce_RecursiveReduce(t *testing.T) {
+	var reduces atomic.Int32
+	server := mapReduceServer(t, &reduces)
+
+	// Tiny chunks make the partial results too large to reduce in one call.
+	result, err := NewMapReduce(newTestOllamaTask(server.URL), OllamaModels.Llama31).
+		WithChunkTokens(12).
+		WithMapPrompt("MAP").
+		WithReducePrompt("REDUCE").
+		WithMaxConcurrency(4).
+		Run(context.Background(), strings.ReplaceAll(paragraphs(10), strings.Repeat("lorem ipsum ", 20), "lorem ipsum dolor sit"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Levels < 2 || !strings.HasPrefix(result.Output, "combined") {
+		t.Errorf("expected a multi-level reduce, got %+v", result)
+	}
+}
+
+func TestMapReduce_PartialFailures(t *testing.T) {
+	var reduces atomic.Int32
+	server := mapReduceServer(t, &reduces)
+	input := paragraphs(2) + "\n\nBAD " + strings.Repeat("x ", 300)
+	mr := NewMapReduce(newTestOllamaTask(server.URL), OllamaModels.Llama31).
+		WithChunkTokens(200).
+		WithMapPrompt("MAP").
+		WithReducePrompt("REDUCE")
+
+	if _, err := mr.Run(context.Background(), input); !errors.Is(err, ErrMapReduceFailed) {
+		t.Fatalf("expected ErrMapReduceFailed without tolerance, got %v", err)
+	}
+
+	result, err := mr.WithMaxFailures(1).Run(context.Background(), input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Failed) != 1 || result.Failed[0] != result.Chunks-1 {
+		t.Errorf("expected the last chunk reported as failed, got %+v", result)
+	}
+}
+
+func TestSplitTokens(t *testing.T) {
+	tok := tokenizer.ForModel(OpenAIModels.GPT4oMini)
+	text := "short one\n\n" + strings.Repeat("a long paragraph that must be split ", 40) + "\n\n\n\nlast"
+
+	chunks := splitTokens(tok, text, 50)
+	if len(chunks) < 3 {
+		t.Fatalf("expected the long paragraph to be split, got %d chunks", len(chunks))
+	}
+	for i, c := range chunks {
+		if n := tok.Count(c); n > 50 {
+			t.Errorf("chunk %d has %d tokens", i, n)
+		}
+	}
+	if chunks[0] != "short one" || !strings.HasSuffix(chunks[len(chunks)-1], "\n\nlast") {
+		t.Errorf("expected breaks between paragraphs where possible, got %q", chunks)
+	}
+	if got := strings.Join(strings.Fields(strings.Join(chunks, " ")), " "); got != strings.Join(strings.Fields(text), " ") {
+		t.Error("expected the chunks to keep every word")
+	}
+}
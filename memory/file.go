@@ -0,0 +1,262 @@
+package memory
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileStore is a Memory kept in a JSON file. Every change rewrites the file
+// atomically, so facts survive restarts and a crash never leaves it half
+// written. It is safe for concurrent use within one process.
+type FileStore struct {
+	mu        sync.Mutex
+	path      string
+	facts     []Fact
+	embedder  Embedder
+	minScore  float64
+	retention Retention
+	now       func() time.Time
+}
+
+var _ Memory = (*FileStore)(nil)
+
+// NewFileStore opens the store at path, loading its facts if the file
+// exists.
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{path: path, now: time.Now}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return s, nil
+	case err != nil:
+		return nil, fmt.Errorf("memory: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.facts); err != nil {
+		return nil, fmt.Errorf("memory: reading %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// WithEmbedder makes the store embed facts as they are remembered and recall
+// them by cosine similarity to the query. Facts stored without an embedding
+// are still matched by keywords.
+func (s *FileStore) WithEmbedder(e Embedder) *FileStore {
+	s.embedder = e
+	return s
+}
+
+// WithMinSimilarity sets the cosine similarity a fact's embedding must
+// reach to be recalled for a query. Facts scoring zero or less are never
+// recalled, like facts sharing no keyword with the query.
+func (s *FileStore) WithMinSimilarity(min float64) *FileStore {
+	s.minScore = min
+	return s
+}
+
+// WithRetention sets the retention policy. It is applied whenever a fact is
+// remembered; expired facts are never recalled.
+func (s *FileStore) WithRetention(r Retention) *FileStore {
+	s.retention = r
+	return s
+}
+
+// Remember stores fact. See Memory.Remember.
+func (s *FileStore) Remember(ctx context.Context, fact Fact) (Fact, error) {
+	fact.Text = strings.TrimSpace(fact.Text)
+	if fact.Text == "" {
+		return fact, ErrEmptyFact
+	}
+	if s.embedder != nil {
+		embedding, err := s.embedder.Embed(ctx, fact.Text)
+		if err != nil {
+			return fact, fmt.Errorf("memory: embedding fact: %w", err)
+		}
+		fact.Embedding = embedding
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	fact.Updated = now
+	facts := slices.Clone(s.facts)
+	existing := -1
+	for i, f := range facts {
+		if (fact.ID != "" && f.ID == fact.ID) || (fact.ID == "" && f.Subject == fact.Subject && strings.EqualFold(f.Text, fact.Text)) {
+			existing = i
+			break
+		}
+	}
+	if existing >= 0 {
+		fact.ID, fact.Created = facts[existing].ID, facts[existing].Created
+		if fact.Metadata == nil {
+			fact.Metadata = facts[existing].Metadata
+		}
+		facts[existing] = fact
+	} else {
+		if fact.ID == "" {
+			fact.ID = newID()
+		}
+		fact.Created = now
+		facts = append(facts, fact)
+	}
+	return fact, s.save(s.prune(facts, now))
+}
+
+// Forget removes a fact. See Memory.Forget.
+func (s *FileStore) Forget(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, f := range s.facts {
+		if f.ID == id {
+			return s.save(slices.Delete(slices.Clone(s.facts), i, i+1))
+		}
+	}
+	return fmt.Errorf("%w: %q", ErrNotFound, id)
+}
+
+// ForgetSubject removes every fact about subject and returns how many there
+// were.
+func (s *FileStore) ForgetSubject(ctx context.Context, subject string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var kept []Fact
+	for _, f := range s.facts {
+		if f.Subject != subject {
+			kept = append(kept, f)
+		}
+	}
+	removed := len(s.facts) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+	if err := s.save(kept); err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+// Recall finds facts about q.Subject. See Memory.Recall.
+func (s *FileStore) Recall(ctx context.Context, q Query) ([]ScoredFact, error) {
+	var queryEmbedding []float64
+	if s.embedder != nil && q.Text != "" {
+		var err error
+		if queryEmbedding, err = s.embedder.Embed(ctx, q.Text); err != nil {
+			return nil, fmt.Errorf("memory: embedding query: %w", err)
+		}
+	}
+	queryWords := keywords(q.Text)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	var matches []ScoredFact
+	for _, f := range s.facts {
+		if f.Subject != q.Subject || s.expired(f, now) {
+			continue
+		}
+		var score float64
+		switch {
+		case q.Text == "":
+		case queryEmbedding != nil && f.Embedding != nil:
+			if score = cosine(queryEmbedding, f.Embedding); score <= 0 || score < s.minScore {
+				continue
+			}
+		default:
+			if score = keywordScore(queryWords, f.Text); score == 0 {
+				continue
+			}
+		}
+		matches = append(matches, ScoredFact{Fact: f, Score: score})
+	}
+	return rank(matches, q.Limit), nil
+}
+
+// Facts returns every fact about subject, oldest first.
+func (s *FileStore) Facts(subject string) []Fact {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var facts []Fact
+	for _, f := range s.facts {
+		if f.Subject == subject {
+			facts = append(facts, f)
+		}
+	}
+	return facts
+}
+
+func (s *FileStore) expired(f Fact, now time.Time) bool {
+	return s.retention.MaxAge > 0 && now.Sub(f.Updated) > s.retention.MaxAge
+}
+
+// prune applies the retention policy to facts, keeping the most recently
+// updated facts of each subject. It reuses the backing array of facts.
+func (s *FileStore) prune(facts []Fact, now time.Time) []Fact {
+	newest := make([]int, len(facts))
+	for i := range newest {
+		newest[i] = i
+	}
+	sort.SliceStable(newest, func(i, j int) bool {
+		return facts[newest[i]].Updated.After(facts[newest[j]].Updated)
+	})
+	keep := make([]bool, len(facts))
+	perSubject := make(map[string]int)
+	for _, i := range newest {
+		f := facts[i]
+		if s.expired(f, now) || (s.retention.MaxFacts > 0 && perSubject[f.Subject] >= s.retention.MaxFacts) {
+			continue
+		}
+		perSubject[f.Subject]++
+		keep[i] = true
+	}
+	kept := facts[:0]
+	for i, f := range facts {
+		if keep[i] {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// save writes facts to a temporary file and renames it over the store. The
+// store holds facts only once they are written, so a failed write leaves
+// memory and file in agreement.
+func (s *FileStore) save(facts []Fact) error {
+	data, err := json.MarshalIndent(facts, "", "  ")
+	if err != nil {
+		return fmt.Errorf("memory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("memory: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("memory: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("memory: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("memory: %w", err)
+	}
+	s.facts = facts
+	return nil
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,177 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func texts(facts []ScoredFact) string {
+	var out []string
+	for _, f := range facts {
+		out = append(out, f.Text)
+	}
+	return strings.Join(out, " | ")
+}
+
+func TestFileStore_RememberRecallPersist(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "memory.json")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	coffee, err := store.Remember(ctx, Fact{Subject: "ana", Text: "Prefers espresso over filter coffee", Metadata: map[string]string{"source": "chat"}})
+	if err != nil || coffee.ID == "" || coffee.Created.IsZero() {
+		t.Fatalf("unexpected fact %+v, %v", coffee, err)
+	}
+	store.Remember(ctx, Fact{Subject: "ana", Text: "Lives in Lisbon"})
+	store.Remember(ctx, Fact{Subject: "bo", Text: "Drinks only tea, never coffee"})
+	again, _ := store.Remember(ctx, Fact{Subject: "ana", Text: "prefers espresso over filter coffee"})
+	if again.ID != coffee.ID || again.Metadata["source"] != "chat" || len(store.Facts("ana")) != 2 {
+		t.Errorf("expected the same text to update the existing fact, got %+v", again)
+	}
+	if _, err := store.Remember(ctx, Fact{Subject: "ana", Text: "  "}); !errors.Is(err, ErrEmptyFact) {
+		t.Errorf("expected ErrEmptyFact, got %v", err)
+	}
+
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := reopened.Recall(ctx, Query{Subject: "ana", Text: "What coffee should I order for her?"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if texts(got) != "prefers espresso over filter coffee" || got[0].Score != 1.0/3 {
+		t.Errorf("expected only ana's coffee fact matching one of three keywords, got %+v", got)
+	}
+	if got, _ := reopened.Recall(ctx, Query{Subject: "ana"}); texts(got) != "prefers espresso over filter coffee | Lives in Lisbon" {
+		t.Errorf("expected the most recent facts for an empty query, got %q", texts(got))
+	}
+
+	if err := reopened.Forget(ctx, coffee.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := reopened.Forget(ctx, coffee.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if n, err := reopened.ForgetSubject(ctx, "bo"); n != 1 || err != nil {
+		t.Errorf("expected one fact forgotten, got %d, %v", n, err)
+	}
+	reopened, _ = NewFileStore(path)
+	if facts := reopened.Facts("ana"); len(facts) != 1 || facts[0].Text != "Lives in Lisbon" || len(reopened.Facts("bo")) != 0 {
+		t.Errorf("expected forgotten facts to stay forgotten, got %+v", facts)
+	}
+}
+
+func TestFileStore_Retention(t *testing.T) {
+	ctx := context.Background()
+	store, _ := NewFileStore(filepath.Join(t.TempDir(), "memory.json"))
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	store.WithRetention(Retention{MaxAge: 48 * time.Hour, MaxFacts: 2})
+
+	first, _ := store.Remember(ctx, Fact{Subject: "ana", Text: "fact one"})
+	now = now.Add(time.Hour)
+	store.Remember(ctx, Fact{Subject: "ana", Text: "fact two"})
+	now = now.Add(time.Hour)
+	store.Remember(ctx, Fact{Subject: "ana", Text: "fact one"}) // refreshed, so kept
+	store.Remember(ctx, Fact{Subject: "ana", Text: "fact three"})
+	store.Remember(ctx, Fact{Subject: "bo", Text: "fact four"})
+
+	facts := store.Facts("ana")
+	if len(facts) != 2 || facts[0].ID != first.ID || facts[1].Text != "fact three" {
+		t.Fatalf("expected the two most recently updated facts, got %+v", facts)
+	}
+
+	now = now.Add(47 * time.Hour)
+	if got, _ := store.Recall(ctx, Query{Subject: "ana"}); len(got) != 2 {
+		t.Errorf("expected facts within MaxAge to be recalled, got %d", len(got))
+	}
+	now = now.Add(2 * time.Hour)
+	if got, _ := store.Recall(ctx, Query{Subject: "ana"}); len(got) != 0 {
+		t.Errorf("expected expired facts to be skipped, got %q", texts(got))
+	}
+	store.Remember(ctx, Fact{Subject: "bo", Text: "fact five"})
+	if facts := store.Facts("ana"); len(facts) != 0 {
+		t.Errorf("expected expired facts to be pruned on write, got %+v", facts)
+	}
+}
+
+// axisEmbedder embeds text on a topic axis: food, travel, or neither.
+type axisEmbedder struct{ calls int }
+
+func (e *axisEmbedder) Embed(ctx context.Context, text string) ([]float64, error) {
+	e.calls++
+	text = strings.ToLower(text)
+	switch {
+	case strings.Contains(text, "sushi") || strings.Contains(text, "dinner"):
+		return []float64{1, 0.1, 0}, nil
+	case strings.Contains(text, "japan") || strings.Contains(text, "trip"):
+		return []float64{0.1, 1, 0}, nil
+	}
+	return []float64{0, 0, 1}, nil
+}
+
+func TestFileStore_EmbeddingRecall(t *testing.T) {
+	ctx := context.Background()
+	embedder := &axisEmbedder{}
+	store, _ := NewFileStore(filepath.Join(t.TempDir(), "memory.json"))
+	store.WithEmbedder(embedder)
+
+	store.Remember(ctx, Fact{Text: "Loves sushi"})
+	store.Remember(ctx, Fact{Text: "Visited Japan last spring"})
+	store.Remember(ctx, Fact{Text: "Works as a nurse"})
+
+	got, err := store.Recall(ctx, Query{Text: "Where should we go for dinner?", Limit: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if texts(got) != "Loves sushi | Visited Japan last spring" || got[0].Score < 0.99 {
+		t.Errorf("expected recall by similarity, got %+v", got)
+	}
+	if embedder.calls != 4 {
+		t.Errorf("expected each fact and the query to be embedded once, got %d calls", embedder.calls)
+	}
+
+	if got, _ := store.Recall(ctx, Query{Text: "Where should we go for dinner?"}); texts(got) != "Loves sushi | Visited Japan last spring" {
+		t.Errorf("expected unrelated facts to be skipped, got %q", texts(got))
+	}
+	store.WithMinSimilarity(0.5)
+	if got, _ := store.Recall(ctx, Query{Text: "Where should we go for dinner?"}); texts(got) != "Loves sushi" {
+		t.Errorf("expected facts below the minimum similarity to be skipped, got %q", texts(got))
+	}
+}
+
+func TestFileStore_FailedSaveKeepsFacts(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "store")
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	store, _ := NewFileStore(filepath.Join(dir, "memory.json"))
+	fact, err := store.Remember(ctx, Fact{Subject: "ana", Text: "Lives in Lisbon"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	os.RemoveAll(dir)
+
+	if err := store.Forget(ctx, fact.ID); err == nil {
+		t.Error("expected the failed write to be reported")
+	}
+	if n, err := store.ForgetSubject(ctx, "ana"); n != 0 || err == nil {
+		t.Errorf("expected nothing forgotten on a failed write, got %d, %v", n, err)
+	}
+	if _, err := store.Remember(ctx, Fact{Subject: "ana", Text: "Has a cat"}); err == nil {
+		t.Error("expected the failed write to be reported")
+	}
+	if facts := store.Facts("ana"); len(facts) != 1 || facts[0].ID != fact.ID {
+		t.Errorf("expected the store to match the last written file, got %+v", facts)
+	}
+}
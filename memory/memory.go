@@ -0,0 +1,164 @@
+// Package memory stores long-term facts that agents remember across runs and
+// process restarts, such as what a user has told them about themselves.
+//
+// Facts belong to a subject, usually a user ID, and are recalled by keyword
+// overlap or, when the store has an Embedder, by embedding similarity. A
+// Retention policy bounds how many facts are kept and for how long.
+package memory
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	// ErrNotFound is returned by Forget when no fact has the given ID.
+	ErrNotFound = errors.New("memory: fact not found")
+	// ErrEmptyFact is returned by Remember for a fact without text.
+	ErrEmptyFact = errors.New("memory: fact text is empty")
+)
+
+// Fact is one remembered piece of information.
+type Fact struct {
+	ID       string            `json:"id"`
+	Subject  string            `json:"subject,omitempty"`
+	Text     string            `json:"text"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Created  time.Time         `json:"created"`
+	Updated  time.Time         `json:"updated"`
+	// Embedding is set by stores that have an Embedder.
+	Embedding []float64 `json:"embedding,omitempty"`
+}
+
+// Query selects facts to recall. Text is matched against the facts' text;
+// an empty Text recalls the subject's most recent facts.
+type Query struct {
+	Subject string
+	Text    string
+	// Limit is the maximum number of facts returned. Zero means 5.
+	Limit int
+}
+
+// ScoredFact is a recalled fact with its relevance: the share of query
+// keywords it contains, or its cosine similarity to the query embedding.
+type ScoredFact struct {
+	Fact
+	Score float64
+}
+
+// Memory is a long-term fact store.
+type Memory interface {
+	// Remember stores a fact and returns it with its ID and timestamps set.
+	// Remembering the same text again for the same subject updates the
+	// existing fact instead of adding a duplicate.
+	Remember(ctx context.Context, fact Fact) (Fact, error)
+	// Forget removes the fact with the given ID.
+	Forget(ctx context.Context, id string) error
+	// Recall returns the facts most relevant to q, best first.
+	Recall(ctx context.Context, q Query) ([]ScoredFact, error)
+}
+
+// Embedder turns text into an embedding vector for similarity search.
+type Embedder interface {
+	Embed(ctx context.Context, text string) ([]float64, error)
+}
+
+// Retention bounds what a store keeps. Zero fields mean no limit.
+type Retention struct {
+	// MaxAge drops facts not updated for longer than this.
+	MaxAge time.Duration
+	// MaxFacts keeps only the most recently updated facts of each subject.
+	MaxFacts int
+}
+
+const defaultRecallLimit = 5
+
+type subjectKey struct{}
+
+// ContextWithSubject returns a copy of ctx naming the subject, such as a user
+// ID, whose memories agents should recall.
+func ContextWithSubject(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, subjectKey{}, subject)
+}
+
+// SubjectFromContext returns the subject named in ctx, or "".
+func SubjectFromContext(ctx context.Context) string {
+	s, _ := ctx.Value(subjectKey{}).(string)
+	return s
+}
+
+// keywords returns the distinct lower-case words of text that carry meaning.
+func keywords(text string) map[string]bool {
+	words := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(w)) > 2 && !stopWords[w] {
+			words[w] = true
+		}
+	}
+	return words
+}
+
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "but": true, "not": true,
+	"you": true, "your": true, "all": true, "any": true, "can": true, "has": true,
+	"have": true, "was": true, "were": true, "with": true, "this": true, "that": true,
+	"what": true, "which": true, "who": true, "how": true, "from": true, "about": true,
+	"does": true, "did": true, "they": true, "them": true, "their": true, "there": true,
+	"would": true, "should": true, "could": true, "into": true, "than": true, "then": true,
+}
+
+// keywordScore is the share of the query's keywords found in text.
+func keywordScore(query map[string]bool, text string) float64 {
+	if len(query) == 0 {
+		return 0
+	}
+	found := 0
+	for w := range keywords(text) {
+		if query[w] {
+			found++
+		}
+	}
+	return float64(found) / float64(len(query))
+}
+
+// cosine returns the cosine similarity of a and b, or 0 if they differ in
+// length or either is zero.
+func cosine(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// rank sorts facts by score, then by most recent update, and keeps limit.
+func rank(facts []ScoredFact, limit int) []ScoredFact {
+	sort.SliceStable(facts, func(i, j int) bool {
+		if facts[i].Score != facts[j].Score {
+			return facts[i].Score > facts[j].Score
+		}
+		return facts[i].Updated.After(facts[j].Updated)
+	})
+	if limit <= 0 {
+		limit = defaultRecallLimit
+	}
+	if len(facts) > limit {
+		facts = facts[:limit]
+	}
+	return facts
+}
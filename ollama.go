@@ -3,6 +3,9 @@ package forza
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
+	"sync"
 
 	"github.com/sashabaranov/go-openai"
 	"github.com/sashabaranov/go-openai/jsonschema"
@@ -14,21 +17,24 @@ const defaultOllamaEndpoint = "http://localhost:11434/v1"
 type ollamaProvider struct {
 	config        *LLMConfig
 	functions     []openai.FunctionDefinition
-	fnExecutable  map[string]func(param string) (string, error)
+	fnExecutable  map[string]toolFunc
 	builtinTools  map[string]bool
 	systemPrompts []agentPrompts
+	memory        agentMemory
 	userPrompt    *string
 	client        *openai.Client // cached client
+	clientMu      sync.Mutex
 }
 
 func newOllama(c *LLMConfig, a *Agent) LLMAgent {
-	fnExecutable := make(map[string]func(param string) (string, error))
+	fnExecutable := make(map[string]toolFunc)
 	builtinTools := make(map[string]bool)
 
 	return &ollamaProvider{
 		config:        c,
 		fnExecutable:  fnExecutable,
 		systemPrompts: buildSystemPrompts(a),
+		memory:        a.agentMemory(),
 		builtinTools:  builtinTools,
 	}
 }
@@ -50,11 +56,7 @@ func (o *ollamaProvider) WithTools(t ...tools.Tool) {
 				"type":     "object",
 			},
 		})
-		o.fnExecutable[tool.Name()] = func(t tools.Tool) func(string) (string, error) {
-			return func(input string) (string, error) {
-				return t.Call(context.Background(), input)
-			}
-		}(tool)
+		o.fnExecutable[tool.Name()] = tool.Call
 		o.builtinTools[tool.Name()] = true
 	}
 }
@@ -82,27 +84,54 @@ func (o *ollamaProvider) AddCustomTools(name string, description string, params
 			Required:   required,
 		},
 	})
-	o.fnExecutable[name] = fn
+	o.fnExecutable[name] = customToolFunc(fn)
+}
+
+// withRunTools returns a copy of the provider for a single completion, with
+// extra tools added by the run. The copy shares the cached client.
+func (o *ollamaProvider) withRunTools(extra []tools.Tool) *ollamaProvider {
+	o.clientMu.Lock()
+	client := o.client
+	o.clientMu.Unlock()
+	call := &ollamaProvider{
+		config:        o.config,
+		functions:     slices.Clone(o.functions),
+		fnExecutable:  maps.Clone(o.fnExecutable),
+		builtinTools:  maps.Clone(o.builtinTools),
+		systemPrompts: o.systemPrompts,
+		memory:        o.memory,
+		userPrompt:    o.userPrompt,
+		client:        client,
+	}
+	call.WithTools(extra...)
+	return call
 }
 
 func (o *ollamaProvider) Completion(ctx context.Context, params ...string) (string, error) {
-	userPrompt, err := resolveUserPrompt(o.userPrompt, params)
+	if extra := runTools(ctx, o.fnExecutable); len(extra) > 0 {
+		return o.withRunTools(extra).Completion(ctx, params...)
+	}
+	systemPrompts, err := o.memory.systemPrompts(ctx, o.systemPrompts, o.userPrompt, params)
+	if err != nil {
+		return "", err
+	}
+	userPrompt, err := fitUserPrompt(o.config, systemPrompts, o.userPrompt, params)
 	if err != nil {
 		return "", err
 	}
 
 	// Build tools
 	var fn []openai.Tool
-	for _, f := range o.functions {
+	for i := range o.functions {
 		fn = append(fn, openai.Tool{
 			Type:     openai.ToolTypeFunction,
-			Function: &f,
+			Function: &o.functions[i],
 		})
 	}
 
 	// Build messages
 	var messages []openai.ChatCompletionMessage
-	for _, p := range o.systemPrompts {
+	for _, p := range systemPrompts {
 		messages = append(messages, openai.ChatCompletionMessage{
 			Role:    p.Role,
 			Content: p.Context,
@@ -124,13 +153,17 @@ func (o *ollamaProvider) Completion(ctx context.Context, params ...string) (stri
 		Messages:    messages,
 		Temperature: float32(o.config.temperature),
 	}
-	if len(fn) > 0 {
+	if len(fn) > 0 && o.config.modelInfo().SupportsTools {
 		req.Tools = fn
+	} else if len(fn) > 0 {
+		return runReAct(ctx, userPrompt, openAIReActTools(o.functions, o.fnExecutable, o.builtinTools), func(ctx context.Context, prompt string) (string, error) {
+			return o.completeText(ctx, client, req, prompt)
+		})
 	}
 
-	resp, err := client.CreateChatCompletion(ctx, req)
+	resp, err := o.createChatCompletion(ctx, client, req)
 	if err != nil {
-		return "", fmt.Errorf("%w: %v", ErrCompletionFailed, err)
+		return "", fmt.Errorf("%w: %w", ErrCompletionFailed, err)
 	}
 	if len(resp.Choices) == 0 {
 		return "", fmt.Errorf("%w: no choices returned", ErrCompletionFailed)
@@ -153,7 +186,7 @@ func (o *ollamaProvider) Completion(ctx context.Context, params ...string) (stri
 				toolInput = extractBuiltinToolInput(toolInput)
 			}
 
-			content, err := toolFn(toolInput)
+			content, err := toolFn(ctx, toolInput)
 			if err != nil {
 				return "", fmt.Errorf("%w: tool %q: %v", ErrToolCallFailed, toolCall.Function.Name, err)
 			}
@@ -167,9 +200,9 @@ func (o *ollamaProvider) Completion(ctx context.Context, params ...string) (stri
 		}
 
 		req.Messages = messages
-		resp, err = client.CreateChatCompletion(ctx, req)
+		resp, err = o.createChatCompletion(ctx, client, req)
 		if err != nil {
-			return "", fmt.Errorf("%w: follow-up after tool call: %v", ErrCompletionFailed, err)
+			return "", fmt.Errorf("%w: follow-up after tool call: %w", ErrCompletionFailed, err)
 		}
 		if len(resp.Choices) == 0 {
 			return "", fmt.Errorf("%w: no choices in follow-up response", ErrCompletionFailed)
@@ -184,7 +217,46 @@ func (o *ollamaProvider) Completion(ctx context.Context, params ...string) (stri
 	return msg.Content, nil
 }
 
+// completeText sends req with its user message replaced by prompt and
+// returns the answer text. It is the single-turn request of the ReAct loop.
+func (o *ollamaProvider) completeText(ctx context.Context, client *openai.Client, req openai.ChatCompletionRequest, prompt string) (string, error) {
+	messages := append([]openai.ChatCompletionMessage(nil), req.Messages...)
+	messages[len(messages)-1].Content = prompt
+	req.Messages = messages
+	resp, err := o.createChatCompletion(ctx, client, req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrCompletionFailed, err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("%w: no choices returned", ErrCompletionFailed)
+	}
+	return resp.Choices[0].Message.Content, nil
+}
+
+// createChatCompletion sends req once the budget and rate limiter admit it,
+// then records the reported usage.
+func (o *ollamaProvider) createChatCompletion(ctx context.Context, client *openai.Client, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	if err := checkBudget(ctx); err != nil {
+		return openai.ChatCompletionResponse{}, err
+	}
+	res, err := reserveRate(ctx, o.config, estimateChatTokens(req.Messages)+o.config.outputTokens())
+	if err != nil {
+		return openai.ChatCompletionResponse{}, err
+	}
+	resp, err := client.CreateChatCompletion(ctx, req)
+	res.reconcile(resp.Usage.TotalTokens)
+	if err == nil {
+		recordUsage(ctx, o.config.model, Usage{
+			PromptTokens:     resp.Usage.PromptTokens,
+			CompletionTokens: resp.Usage.CompletionTokens,
+		})
+	}
+	return resp, err
+}
+
 func (o *ollamaProvider) getClient() (*openai.Client, error) {
+	o.clientMu.Lock()
+	defer o.clientMu.Unlock()
 	if o.client != nil {
 		return o.client, nil
 	}
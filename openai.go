@@ -3,6 +3,9 @@ package forza
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
+	"sync"
 
 	"github.com/sashabaranov/go-openai"
 	"github.com/sashabaranov/go-openai/jsonschema"
@@ -12,21 +15,24 @@ import (
 type openaiProvider struct {
 	config        *LLMConfig
 	functions     []openai.FunctionDefinition
-	fnExecutable  map[string]func(param string) (string, error)
+	fnExecutable  map[string]toolFunc
 	builtinTools  map[string]bool
 	systemPrompts []agentPrompts
+	memory        agentMemory
 	userPrompt    *string
 	client        *openai.Client // cached client, also used for testing
+	clientMu      sync.Mutex
 }
 
 func newOpenAI(c *LLMConfig, a *Agent) LLMAgent {
-	fnExecutable := make(map[string]func(param string) (string, error))
+	fnExecutable := make(map[string]toolFunc)
 	builtinTools := make(map[string]bool)
 
 	return &openaiProvider{
 		config:        c,
 		fnExecutable:  fnExecutable,
 		systemPrompts: buildSystemPrompts(a),
+		memory:        a.agentMemory(),
 		builtinTools:  builtinTools,
 	}
 }
@@ -48,11 +54,7 @@ func (o *openaiProvider) WithTools(t ...tools.Tool) {
 				"type":     "object",
 			},
 		})
-		o.fnExecutable[tool.Name()] = func(t tools.Tool) func(string) (string, error) {
-			return func(input string) (string, error) {
-				return t.Call(context.Background(), input)
-			}
-		}(tool)
+		o.fnExecutable[tool.Name()] = tool.Call
 		o.builtinTools[tool.Name()] = true
 	}
 }
@@ -65,29 +67,62 @@ func (o *openaiProvider) AddCustomTools(name string, description string, params
 		Description: description,
 		Parameters:  schema,
 	})
-	o.fnExecutable[name] = fn
+	o.fnExecutable[name] = customToolFunc(fn)
+}
+
+// withRunTools returns a copy of the provider for a single completion, with
+// extra tools added by the run. The copy shares the cached client.
+func (o *openaiProvider) withRunTools(extra []tools.Tool) *openaiProvider {
+	o.clientMu.Lock()
+	client := o.client
+	o.clientMu.Unlock()
+	call := &openaiProvider{
+		config:        o.config,
+		functions:     slices.Clone(o.functions),
+		fnExecutable:  maps.Clone(o.fnExecutable),
+		builtinTools:  maps.Clone(o.builtinTools),
+		systemPrompts: o.systemPrompts,
+		memory:        o.memory,
+		userPrompt:    o.userPrompt,
+		client:        client,
+	}
+	call.WithTools(extra...)
+	return call
 }
 
 func (o *openaiProvider) Completion(ctx context.Context, params ...string) (string, error) {
-	userPrompt, err := resolveUserPrompt(o.userPrompt, params)
+	if extra := runTools(ctx, o.fnExecutable); len(extra) > 0 {
+		return o.withRunTools(extra).Completion(ctx, params...)
+	}
+	systemPrompts, err := o.memory.systemPrompts(ctx, o.systemPrompts, o.userPrompt, params)
+	if err != nil {
+		return "", err
+	}
+	userPrompt, err := fitUserPrompt(o.config, systemPrompts, o.userPrompt, params)
 	if err != nil {
 		return "", err
 	}
 
 	// Build tools
 	var fn []openai.Tool
-	for _, f := range o.functions {
+	for i := range o.functions {
 		fn = append(fn, openai.Tool{
 			Type:     openai.ToolTypeFunction,
-			Function: &f,
+			Function: &o.functions[i],
 		})
 	}
 
-	// Build messages
+	info := o.config.modelInfo()
+
+	// Build messages. Models without system prompt support get them as user messages.
 	var messages []openai.ChatCompletionMessage
-	for _, p := range o.systemPrompts {
+	for _, p := range systemPrompts {
+		role := p.Role
+		if !info.SupportsSystemPrompt {
+			role = openai.ChatMessageRoleUser
+		}
 		messages = append(messages, openai.ChatCompletionMessage{
-			Role:    p.Role,
+			Role:    role,
 			Content: p.Context,
 		})
 	}
@@ -102,20 +137,28 @@ func (o *openaiProvider) Completion(ctx context.Context, params ...string) (stri
 		return "", err
 	}
 
-	// Build request
+	// Build request. Reasoning models reject temperature and max_tokens.
 	req := openai.ChatCompletionRequest{
-		Model:       o.config.model,
-		Messages:    messages,
-		Temperature: float32(o.config.temperature),
-		MaxTokens:   o.config.maxTokens,
+		Model:    o.config.model,
+		Messages: messages,
 	}
-	if len(fn) > 0 {
+	if info.Reasoning {
+		req.MaxCompletionTokens = o.config.outputTokens()
+	} else {
+		req.Temperature = float32(o.config.temperature)
+		req.MaxTokens = o.config.outputTokens()
+	}
+	if len(fn) > 0 && info.SupportsTools {
 		req.Tools = fn
+	} else if len(fn) > 0 {
+		return runReAct(ctx, userPrompt, openAIReActTools(o.functions, o.fnExecutable, o.builtinTools), func(ctx context.Context, prompt string) (string, error) {
+			return o.completeText(ctx, client, req, prompt)
+		})
 	}
 
-	resp, err := client.CreateChatCompletion(ctx, req)
+	resp, err := o.createChatCompletion(ctx, client, req)
 	if err != nil {
-		return "", fmt.Errorf("%w: %v", ErrCompletionFailed, err)
+		return "", fmt.Errorf("%w: %w", ErrCompletionFailed, err)
 	}
 	if len(resp.Choices) == 0 {
 		return "", fmt.Errorf("%w: no choices returned", ErrCompletionFailed)
@@ -138,7 +181,7 @@ func (o *openaiProvider) Completion(ctx context.Context, params ...string) (stri
 				toolInput = extractBuiltinToolInput(toolInput)
 			}
 
-			content, err := toolFn(toolInput)
+			content, err := toolFn(ctx, toolInput)
 			if err != nil {
 				return "", fmt.Errorf("%w: tool %q: %v", ErrToolCallFailed, toolCall.Function.Name, err)
 			}
@@ -152,9 +195,9 @@ func (o *openaiProvider) Completion(ctx context.Context, params ...string) (stri
 		}
 
 		req.Messages = messages
-		resp, err = client.CreateChatCompletion(ctx, req)
+		resp, err = o.createChatCompletion(ctx, client, req)
 		if err != nil {
-			return "", fmt.Errorf("%w: follow-up after tool call: %v", ErrCompletionFailed, err)
+			return "", fmt.Errorf("%w: follow-up after tool call: %w", ErrCompletionFailed, err)
 		}
 		if len(resp.Choices) == 0 {
 			return "", fmt.Errorf("%w: no choices in follow-up response", ErrCompletionFailed)
@@ -169,7 +212,56 @@ func (o *openaiProvider) Completion(ctx context.Context, params ...string) (stri
 	return msg.Content, nil
 }
 
+// completeText sends req with its user message replaced by prompt and
+// returns the answer text. It is the single-turn request of the ReAct loop.
+func (o *openaiProvider) completeText(ctx context.Context, client *openai.Client, req openai.ChatCompletionRequest, prompt string) (string, error) {
+	messages := append([]openai.ChatCompletionMessage(nil), req.Messages...)
+	messages[len(messages)-1].Content = prompt
+	req.Messages = messages
+	resp, err := o.createChatCompletion(ctx, client, req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrCompletionFailed, err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("%w: no choices returned", ErrCompletionFailed)
+	}
+	return resp.Choices[0].Message.Content, nil
+}
+
+// openAIReActTools describes OpenAI-style function definitions to the ReAct
+// loop.
+func openAIReActTools(defs []openai.FunctionDefinition, fns map[string]toolFunc, builtin map[string]bool) []reactTool {
+	tools := make([]reactTool, len(defs))
+	for i, d := range defs {
+		tools[i] = reactTool{name: d.Name, description: d.Description, parameters: d.Parameters, builtin: builtin[d.Name], fn: fns[d.Name]}
+	}
+	return tools
+}
+
+// createChatCompletion sends req once the budget and rate limiter admit it,
+// then records the reported usage.
+func (o *openaiProvider) createChatCompletion(ctx context.Context, client *openai.Client, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	if err := checkBudget(ctx); err != nil {
+		return openai.ChatCompletionResponse{}, err
+	}
+	res, err := reserveRate(ctx, o.config, estimateChatTokens(req.Messages)+o.config.outputTokens())
+	if err != nil {
+		return openai.ChatCompletionResponse{}, err
+	}
+	resp, err := client.CreateChatCompletion(ctx, req)
+	res.reconcile(resp.Usage.TotalTokens)
+	if err == nil {
+		recordUsage(ctx, o.config.model, Usage{
+			PromptTokens:     resp.Usage.PromptTokens,
+			CompletionTokens: resp.Usage.CompletionTokens,
+		})
+	}
+	return resp, err
+}
+
 func (o *openaiProvider) getClient() (*openai.Client, error) {
+	o.clientMu.Lock()
+	defer o.clientMu.Unlock()
 	if o.client != nil {
 		return o.client, nil
 	}
@@ -182,24 +274,32 @@ func (o *openaiProvider) getClient() (*openai.Client, error) {
 }
 
 func (o *openaiProvider) createClient() (*openai.Client, error) {
-	if o.config.provider == ProviderAzure {
-		apiKey := o.config.credentials.apiKey
-		endpoint := o.config.credentials.endpoint
+	config, err := openAIClientConfig(o.config)
+	if err != nil {
+		return nil, err
+	}
+	return openai.NewClientWithConfig(config), nil
+}
+
+// openAIClientConfig builds the go-openai client configuration for an OpenAI
+// or Azure OpenAI config.
+func openAIClientConfig(c *LLMConfig) (openai.ClientConfig, error) {
+	apiKey := c.credentials.apiKey
+	if c.provider == ProviderAzure {
+		endpoint := c.credentials.endpoint
 		if apiKey == "" {
-			return nil, fmt.Errorf("%w: Azure OpenAI API key", ErrMissingAPIKey)
+			return openai.ClientConfig{}, fmt.Errorf("%w: Azure OpenAI API key", ErrMissingAPIKey)
 		}
 		if endpoint == "" {
-			return nil, fmt.Errorf("%w: Azure OpenAI endpoint", ErrMissingEndpoint)
+			return openai.ClientConfig{}, fmt.Errorf("%w: Azure OpenAI endpoint", ErrMissingEndpoint)
 		}
-		config := openai.DefaultAzureConfig(apiKey, endpoint)
-		return openai.NewClientWithConfig(config), nil
+		return openai.DefaultAzureConfig(apiKey, endpoint), nil
 	}
 
-	apiKey := o.config.credentials.apiKey
 	if apiKey == "" {
-		return nil, fmt.Errorf("%w: OpenAI API key", ErrMissingAPIKey)
+		return openai.ClientConfig{}, fmt.Errorf("%w: OpenAI API key", ErrMissingAPIKey)
 	}
-	return openai.NewClient(apiKey), nil
+	return openai.DefaultConfig(apiKey), nil
 }
 
 // generateOpenAISchema converts a FunctionShape to an OpenAI-compatible JSON Schema.
@@ -223,3 +323,12 @@ func generateOpenAISchema(shape FunctionShape) jsonschema.Definition {
 		Required:   required,
 	}
 }
+
+// estimateChatTokens approximates the prompt size of an OpenAI-style message list.
+func estimateChatTokens(messages []openai.ChatCompletionMessage) int {
+	var n int
+	for _, m := range messages {
+		n += estimateTokens(m.Content) + 4
+	}
+	return n
+}
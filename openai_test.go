@@ -421,3 +421,28 @@ func TestOpenAI_Completion_ToolReturnsError(t *testing.T) {
 		t.Errorf("expected ErrToolCallFailed, got %v", err)
 	}
 }
+
+func TestOpenAI_Completion_SendsEveryToolDefinition(t *testing.T) {
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openai.ChatCompletionRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		for _, tool := range req.Tools {
+			sent = append(sent, tool.Function.Name+": "+tool.Function.Description)
+		}
+		json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: "ok"}}},
+		})
+	}))
+	defer server.Close()
+
+	task := newTestOpenAITask(server.URL)
+	task.WithTools(&mockTool{name: "weather", desc: "looks up the weather"}, &mockTool{name: "calendar", desc: "reads the calendar"})
+	task.WithUserPrompt("plan my day")
+	if _, err := task.Completion(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sent) != 2 || sent[0] != "weather: looks up the weather" || sent[1] != "calendar: reads the calendar" {
+		t.Errorf("expected both tool definitions, got %q", sent)
+	}
+}
@@ -0,0 +1,352 @@
+package forza
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const defaultPlanIterations = 10
+
+const (
+	plannerPrompt = `You plan and track the work towards the goal given as context, together with the tasks completed so far and the remaining plan. ` +
+		`Break the goal into small, concrete tasks that can each be carried out on their own, in the order they should run. ` +
+		`After each completed task, revise the remaining tasks in light of its result; retry or work around failed tasks. ` +
+		`When the completed tasks are enough to achieve the goal, write the final answer. ` +
+		`Answer only with a JSON object of the form {"done": true or false, "answer": "<the final answer when done>", "tasks": ["<remaining task>", ...]}.`
+	executorPrompt = `Carry out the current task given as context, which is one step towards the goal. Use the results of earlier tasks where they help. ` +
+		`Answer with the task's result only.`
+)
+
+// PlanStepStatus is the state of one task in a plan.
+type PlanStepStatus string
+
+const (
+	PlanStepPending PlanStepStatus = "pending"
+	PlanStepDone    PlanStepStatus = "done"
+	PlanStepFailed  PlanStepStatus = "failed"
+)
+
+// PlanStep is one task of a plan and its outcome.
+type PlanStep struct {
+	Task   string         `json:"task"`
+	Status PlanStepStatus `json:"status"`
+	Result string         `json:"result,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+// PlanState is the evolving plan of a PlanExecutor: the executed steps in
+// order, followed by the pending ones. It can be saved as JSON and passed to
+// Resume to continue an interrupted run.
+type PlanState struct {
+	Goal       string     `json:"goal"`
+	Steps      []PlanStep `json:"steps"`
+	Iterations int        `json:"iterations"`
+	Done       bool       `json:"done"`
+	Answer     string     `json:"answer,omitempty"`
+}
+
+// nextStep returns the index of the first pending step, or -1.
+func (s *PlanState) nextStep() int {
+	for i, step := range s.Steps {
+		if step.Status == PlanStepPending {
+			return i
+		}
+	}
+	return -1
+}
+
+// PlanExecutor works autonomously towards a goal: a planner agent breaks the
+// goal into an ordered task list, an executor agent carries out the tasks
+// one at a time, using its tools, and the planner revises the remaining
+// tasks after each result until it declares the goal achieved.
+//
+// The planner and executor have their user prompts replaced, so they should
+// be dedicated to the PlanExecutor; register the executor's tools before
+// creating it. When the context carries a CostTracker, planner and executor
+// calls are tracked under children named "plan N" and "step N".
+type PlanExecutor struct {
+	planner       LLMAgent
+	executor      LLMAgent
+	maxIterations int
+	timeout       time.Duration
+	budget        float64
+	stateFile     string
+	logger        *slog.Logger
+}
+
+// NewPlanExecutor creates a PlanExecutor from a planner and an executor
+// agent. The planner is asked for JSON, so it works best on a model that
+// follows format instructions well.
+func NewPlanExecutor(planner, executor LLMAgent) *PlanExecutor {
+	if planner != nil {
+		planner.WithUserPrompt(plannerPrompt)
+	}
+	if executor != nil {
+		executor.WithUserPrompt(executorPrompt)
+	}
+	return &PlanExecutor{planner: planner, executor: executor, maxIterations: defaultPlanIterations}
+}
+
+// WithMaxIterations sets how many tasks one run executes at most. Default
+// is 10.
+func (p *PlanExecutor) WithMaxIterations(n int) *PlanExecutor {
+	p.maxIterations = n
+	return p
+}
+
+// WithTimeout limits the duration of one run.
+func (p *PlanExecutor) WithTimeout(d time.Duration) *PlanExecutor {
+	p.timeout = d
+	return p
+}
+
+// WithBudget limits one run's spend in USD. The run happens under a child of
+// the context's CostTracker, or a new tracker if there is none, and stops
+// with ErrBudgetExceeded once the limit is reached.
+func (p *PlanExecutor) WithBudget(usd float64) *PlanExecutor {
+	p.budget = usd
+	return p
+}
+
+// WithStateFile saves the plan state as JSON to path after every planner and
+// executor call. Run resumes from the file when it exists.
+func (p *PlanExecutor) WithStateFile(path string) *PlanExecutor {
+	p.stateFile = path
+	return p
+}
+
+// WithLogger sets an optional logger. If nil, no logging occurs.
+func (p *PlanExecutor) WithLogger(l *slog.Logger) *PlanExecutor {
+	p.logger = l
+	return p
+}
+
+// Task returns the PlanExecutor as a TaskChainFn that produces the final
+// answer, for use in a Pipeline or DAG. Its context argument is the goal.
+func (p *PlanExecutor) Task() TaskChainFn {
+	return func(ctx context.Context, params ...string) (string, error) {
+		if len(params) != 1 {
+			return "", fmt.Errorf("%w: a plan executor needs the goal as its only context argument", ErrTooManyArgs)
+		}
+		state, err := p.Run(ctx, params[0])
+		return state.Answer, err
+	}
+}
+
+// Run plans and executes tasks until the planner declares goal achieved.
+// With a state file that already exists, Run resumes the saved plan
+// instead, provided it was made for the same goal.
+//
+// The state is returned even when the run fails. Reaching the iteration
+// limit returns ErrPlanIncomplete; running out of time or budget returns
+// the context or budget error.
+func (p *PlanExecutor) Run(ctx context.Context, goal string) (PlanState, error) {
+	state := PlanState{Goal: goal}
+	if p.stateFile != "" {
+		saved, err := LoadPlanState(p.stateFile)
+		switch {
+		case err == nil && saved.Goal != goal:
+			return state, fmt.Errorf("%w: state file %s holds a plan for a different goal", ErrInvalidConfig, p.stateFile)
+		case err == nil:
+			state = saved
+		case !errors.Is(err, os.ErrNotExist):
+			return state, err
+		}
+	}
+	return p.Resume(ctx, state)
+}
+
+// Resume continues a plan from a saved state. A completed state is returned
+// as is.
+func (p *PlanExecutor) Resume(ctx context.Context, state PlanState) (PlanState, error) {
+	if p.planner == nil || p.executor == nil {
+		return state, fmt.Errorf("%w: a plan executor needs a planner and an executor", ErrNilTask)
+	}
+	if p.maxIterations < 1 {
+		return state, fmt.Errorf("%w: max iterations must be at least 1", ErrInvalidConfig)
+	}
+	if state.Done {
+		return state, nil
+	}
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+	if p.budget > 0 {
+		tracker := CostTrackerFromContext(ctx)
+		if tracker == nil {
+			tracker = NewCostTracker()
+		} else {
+			tracker = tracker.Child("plan executor")
+		}
+		ctx = ContextWithCostTracker(ctx, tracker.WithBudget(p.budget))
+	}
+
+	if state.nextStep() < 0 {
+		if err := p.plan(ctx, &state); err != nil {
+			return state, err
+		}
+	}
+	for iteration := 0; !state.Done; iteration++ {
+		i := state.nextStep()
+		if i < 0 {
+			return state, fmt.Errorf("%w: planner returned no tasks before the goal was achieved", ErrInvalidOutput)
+		}
+		if iteration == p.maxIterations {
+			return state, fmt.Errorf("%w: %d tasks executed, %d pending", ErrPlanIncomplete, iteration, len(state.Steps)-i)
+		}
+		if err := p.execute(ctx, &state, i); err != nil {
+			return state, err
+		}
+		if err := p.plan(ctx, &state); err != nil {
+			return state, err
+		}
+	}
+	return state, nil
+}
+
+// plan asks the planner to revise the pending steps.
+func (p *PlanExecutor) plan(ctx context.Context, state *PlanState) error {
+	answer, err := p.planner.Completion(internalTaskContext(ctx, fmt.Sprintf("plan %d", state.Iterations)), planContext(state, -1))
+	if err != nil {
+		return fmt.Errorf("planner: %w", err)
+	}
+	var decision struct {
+		Done   bool     `json:"done"`
+		Answer string   `json:"answer"`
+		Tasks  []string `json:"tasks"`
+	}
+	if err := json.Unmarshal([]byte(stripCodeFence(answer)), &decision); err != nil {
+		return fmt.Errorf("%w: planner answer: %v", ErrInvalidOutput, err)
+	}
+
+	executed := state.Steps[:0:0]
+	for _, step := range state.Steps {
+		if step.Status != PlanStepPending {
+			executed = append(executed, step)
+		}
+	}
+	state.Steps = executed
+	if decision.Done {
+		state.Done = true
+		state.Answer = decision.Answer
+	} else {
+		for _, task := range decision.Tasks {
+			if task = strings.TrimSpace(task); task != "" {
+				state.Steps = append(state.Steps, PlanStep{Task: task, Status: PlanStepPending})
+			}
+		}
+	}
+	p.logDebug("plan revised", "done", state.Done, "pending", len(state.Steps)-len(executed))
+	return p.save(state)
+}
+
+// execute runs step i. A failed step is recorded for the planner to work
+// around; cancellation and budget exhaustion end the run instead.
+func (p *PlanExecutor) execute(ctx context.Context, state *PlanState, i int) error {
+	state.Iterations++
+	step := &state.Steps[i]
+	p.logDebug("executing task", "iteration", state.Iterations, "task", step.Task)
+	result, err := p.executor.Completion(namedTaskContext(ctx, fmt.Sprintf("step %d", state.Iterations)), planContext(state, i))
+	if err != nil {
+		if ctx.Err() != nil || errors.Is(err, ErrBudgetExceeded) {
+			state.Iterations--
+			return err
+		}
+		step.Status, step.Error = PlanStepFailed, err.Error()
+	} else {
+		step.Status, step.Result = PlanStepDone, result
+	}
+	return p.save(state)
+}
+
+// planContext describes the goal and progress. With current >= 0 it is the
+// executor's context for that step; otherwise it is the planner's, with the
+// pending steps as the remaining plan.
+func planContext(state *PlanState, current int) string {
+	var b strings.Builder
+	b.WriteString("Goal: " + state.Goal)
+
+	var remaining []string
+	n := 0
+	for _, step := range state.Steps {
+		switch step.Status {
+		case PlanStepDone:
+			n++
+			fmt.Fprintf(&b, "\n\nCompleted task %d: %s\nResult: %s", n, step.Task, step.Result)
+		case PlanStepFailed:
+			n++
+			fmt.Fprintf(&b, "\n\nFailed task %d: %s\nError: %s", n, step.Task, step.Error)
+		default:
+			remaining = append(remaining, step.Task)
+		}
+	}
+
+	if current >= 0 {
+		b.WriteString("\n\nCurrent task: " + state.Steps[current].Task)
+		return b.String()
+	}
+	if len(remaining) == 0 {
+		b.WriteString("\n\nRemaining plan: none yet")
+	} else {
+		b.WriteString("\n\nRemaining plan:")
+		for i, task := range remaining {
+			fmt.Fprintf(&b, "\n%d. %s", i+1, task)
+		}
+	}
+	return b.String()
+}
+
+// save writes the state file, if one is configured, replacing it atomically.
+func (p *PlanExecutor) save(state *PlanState) error {
+	if p.stateFile == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("saving plan state: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p.stateFile), filepath.Base(p.stateFile)+".*")
+	if err != nil {
+		return fmt.Errorf("saving plan state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("saving plan state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("saving plan state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), p.stateFile); err != nil {
+		return fmt.Errorf("saving plan state: %w", err)
+	}
+	return nil
+}
+
+// LoadPlanState reads a plan state saved by a PlanExecutor's state file.
+func LoadPlanState(path string) (PlanState, error) {
+	var state PlanState
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state, fmt.Errorf("loading plan state: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("loading plan state %s: %w", path, err)
+	}
+	return state, nil
+}
+
+func (p *PlanExecutor) logDebug(msg string, args ...any) {
+	if p.logger != nil {
+		p.logger.Debug(msg, args...)
+	}
+}
@@ -0,0 +1,154 @@
+package forza
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// planScript returns a planner that answers with the given replies in turn.
+func planScript(replies ...string) *stubAgent {
+	planner := &stubAgent{}
+	planner.complete = func(ctx context.Context, params ...string) (string, error) {
+		if planner.calls > len(replies) {
+			return "", errors.New("planner unavailable")
+		}
+		return replies[planner.calls-1], nil
+	}
+	return planner
+}
+
+// taskDoer answers with the current task it was given.
+func taskDoer() *stubAgent {
+	executor := &stubAgent{}
+	executor.complete = func(ctx context.Context, params ...string) (string, error) {
+		_, task, _ := strings.Cut(params[0], "Current task: ")
+		return "did " + task, nil
+	}
+	return executor
+}
+
+func TestPlanExecutor_RunsAndReplans(t *testing.T) {
+	var contexts []string
+	planner := planScript(
+		"```json\n{\"done\": false, \"tasks\": [\"find sources\", \"draft report\"]}\n```",
+		`{"done": false, "tasks": ["read sources", "draft report"]}`,
+		`{"done": false, "tasks": ["draft report"]}`,
+		`{"done": true, "answer": "the report"}`,
+	)
+	inner := planner.complete
+	planner.complete = func(ctx context.Context, params ...string) (string, error) {
+		contexts = append(contexts, params[0])
+		return inner(ctx, params...)
+	}
+	executor := taskDoer()
+
+	state, err := NewPlanExecutor(planner, executor).Run(context.Background(), "write a report")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !state.Done || state.Answer != "the report" || state.Iterations != 3 {
+		t.Fatalf("unexpected state %+v", state)
+	}
+	var tasks []string
+	for _, step := range state.Steps {
+		if step.Status != PlanStepDone || step.Result != "did "+step.Task {
+			t.Errorf("unexpected step %+v", step)
+		}
+		tasks = append(tasks, step.Task)
+	}
+	if got := strings.Join(tasks, ", "); got != "find sources, read sources, draft report" {
+		t.Errorf("expected the revised plan to be followed, got %s", got)
+	}
+	if !strings.Contains(contexts[0], "Goal: write a report") || !strings.Contains(contexts[0], "Remaining plan: none yet") {
+		t.Errorf("unexpected first planner context %q", contexts[0])
+	}
+	if !strings.Contains(contexts[1], "Completed task 1: find sources\nResult: did find sources") || !strings.Contains(contexts[1], "1. draft report") {
+		t.Errorf("expected progress in the planner context, got %q", contexts[1])
+	}
+	if !strings.Contains(planner.prompt, `"tasks"`) || executor.prompt != executorPrompt {
+		t.Errorf("expected planner and executor prompts to be set")
+	}
+}
+
+func TestPlanExecutor_FailedStepsAndLimits(t *testing.T) {
+	planner := &stubAgent{}
+	planner.complete = func(context.Context, ...string) (string, error) {
+		return `{"done": false, "tasks": ["try again"]}`, nil
+	}
+	executor := &stubAgent{}
+	executor.complete = func(ctx context.Context, params ...string) (string, error) {
+		if err := checkBudget(ctx); err != nil {
+			return "", err
+		}
+		recordUsage(ctx, OpenAIModels.GPT4o, Usage{PromptTokens: 1000, CompletionTokens: 1000})
+		return "", errors.New("tool offline")
+	}
+
+	state, err := NewPlanExecutor(planner, executor).WithMaxIterations(2).Run(context.Background(), "goal")
+	if !errors.Is(err, ErrPlanIncomplete) {
+		t.Fatalf("expected ErrPlanIncomplete, got %v", err)
+	}
+	if state.Done || len(state.Steps) != 3 || state.Steps[0].Status != PlanStepFailed || state.Steps[0].Error != "tool offline" {
+		t.Errorf("expected two failed steps and one pending, got %+v", state.Steps)
+	}
+
+	_, err = NewPlanExecutor(planner, executor).WithBudget(0.01).Run(context.Background(), "goal")
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("expected ErrBudgetExceeded, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	executor.complete = func(ctx context.Context, _ ...string) (string, error) { return "", ctx.Err() }
+	state, err = NewPlanExecutor(planner, executor).Run(ctx, "goal")
+	if !errors.Is(err, context.Canceled) || state.Iterations != 0 || state.Steps[0].Status != PlanStepPending {
+		t.Errorf("expected cancellation to leave the step pending, got %+v, %v", state, err)
+	}
+
+	_, err = NewPlanExecutor(planScript(`not json`), executor).Run(context.Background(), "goal")
+	if !errors.Is(err, ErrInvalidOutput) {
+		t.Errorf("expected ErrInvalidOutput, got %v", err)
+	}
+}
+
+func TestPlanExecutor_StateFileResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	executor := taskDoer()
+
+	// The planner fails after the first task, interrupting the run.
+	_, err := NewPlanExecutor(planScript(`{"done": false, "tasks": ["a", "b"]}`), executor).
+		WithStateFile(path).
+		Run(context.Background(), "goal")
+	if err == nil {
+		t.Fatal("expected the planner failure")
+	}
+	saved, err := LoadPlanState(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if saved.Iterations != 1 || saved.Steps[0].Status != PlanStepDone || saved.Steps[1].Status != PlanStepPending {
+		t.Fatalf("unexpected saved state %+v", saved)
+	}
+
+	state, err := NewPlanExecutor(planScript(`{"done": true, "answer": "finished"}`), executor).
+		WithStateFile(path).
+		Run(context.Background(), "goal")
+	if err != nil || !state.Done || state.Answer != "finished" {
+		t.Fatalf("unexpected resumed state %+v, %v", state, err)
+	}
+	if executor.calls != 2 || state.Steps[1].Result != "did b" {
+		t.Errorf("expected only the pending task to run on resume, got %d calls and %+v", executor.calls, state.Steps)
+	}
+
+	_, err = NewPlanExecutor(planScript(), executor).WithStateFile(path).Run(context.Background(), "another goal")
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for a different goal, got %v", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("expected no temporary files left behind, got %d entries", len(entries))
+	}
+}
@@ -0,0 +1,91 @@
+package forza
+
+import "sync"
+
+// Usage reports the tokens consumed by one or more completion requests.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// TotalTokens returns the sum of prompt and completion tokens.
+func (u Usage) TotalTokens() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// Add returns the sum of two usages.
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		PromptTokens:     u.PromptTokens + other.PromptTokens,
+		CompletionTokens: u.CompletionTokens + other.CompletionTokens,
+	}
+}
+
+// ModelPrice is the price of a model in USD per million tokens.
+type ModelPrice struct {
+	InputPerMillion  float64
+	OutputPerMillion float64
+}
+
+// Cost returns the USD cost of the given usage at this price.
+func (p ModelPrice) Cost(u Usage) float64 {
+	return (float64(u.PromptTokens)*p.InputPerMillion + float64(u.CompletionTokens)*p.OutputPerMillion) / 1e6
+}
+
+// modelPrices holds list prices for the predefined models. Ollama models run
+// locally and are not priced. Guarded by pricingMu; use SetModelPrice to
+// override or extend it.
+var (
+	pricingMu   sync.RWMutex
+	modelPrices = map[string]ModelPrice{
+		OpenAIModels.GPT35Turbo: {InputPerMillion: 0.50, OutputPerMillion: 1.50},
+		OpenAIModels.GPT4:       {InputPerMillion: 30, OutputPerMillion: 60},
+		OpenAIModels.GPT4o:      {InputPerMillion: 2.50, OutputPerMillion: 10},
+		OpenAIModels.GPT4Turbo:  {InputPerMillion: 10, OutputPerMillion: 30},
+		OpenAIModels.GPT4oMini:  {InputPerMillion: 0.15, OutputPerMillion: 0.60},
+		OpenAIModels.O1Mini:     {InputPerMillion: 1.10, OutputPerMillion: 4.40},
+		OpenAIModels.O1:         {InputPerMillion: 15, OutputPerMillion: 60},
+		OpenAIModels.GPT5:       {InputPerMillion: 1.25, OutputPerMillion: 10},
+		OpenAIModels.Codex52:    {InputPerMillion: 1.25, OutputPerMillion: 10},
+
+		AnthropicModels.Claude3Haiku:   {InputPerMillion: 0.25, OutputPerMillion: 1.25},
+		AnthropicModels.Claude35Sonnet: {InputPerMillion: 3, OutputPerMillion: 15},
+		AnthropicModels.Claude37Sonnet: {InputPerMillion: 3, OutputPerMillion: 15},
+		AnthropicModels.Claude4Sonnet:  {InputPerMillion: 3, OutputPerMillion: 15},
+		AnthropicModels.Claude4Opus:    {InputPerMillion: 15, OutputPerMillion: 75},
+		AnthropicModels.Claude45Sonnet: {InputPerMillion: 3, OutputPerMillion: 15},
+		AnthropicModels.Claude45Opus:   {InputPerMillion: 5, OutputPerMillion: 25},
+		AnthropicModels.Claude46Sonnet: {InputPerMillion: 3, OutputPerMillion: 15},
+		AnthropicModels.Claude46Opus:   {InputPerMillion: 5, OutputPerMillion: 25},
+
+		GeminiModels.Gemini20Flash:    {InputPerMillion: 0.10, OutputPerMillion: 0.40},
+		GeminiModels.Gemini20FlashExp: {InputPerMillion: 0.10, OutputPerMillion: 0.40},
+		GeminiModels.Gemini25Pro:      {InputPerMillion: 1.25, OutputPerMillion: 10},
+		GeminiModels.Gemini25Flash:    {InputPerMillion: 0.30, OutputPerMillion: 2.50},
+		GeminiModels.Gemini3Flash:     {InputPerMillion: 0.50, OutputPerMillion: 3},
+		GeminiModels.Gemini3Pro:       {InputPerMillion: 2, OutputPerMillion: 12},
+	}
+)
+
+// SetModelPrice overrides the price of a model or adds a price for a model
+// that is not in the built-in table.
+func SetModelPrice(model string, price ModelPrice) {
+	pricingMu.Lock()
+	defer pricingMu.Unlock()
+	modelPrices[model] = price
+}
+
+// GetModelPrice returns the price of a model and whether it is known.
+func GetModelPrice(model string) (ModelPrice, bool) {
+	pricingMu.RLock()
+	defer pricingMu.RUnlock()
+	p, ok := modelPrices[model]
+	return p, ok
+}
+
+// EstimateCost returns the USD cost of the given usage for a model. Unknown
+// models cost zero.
+func EstimateCost(model string, u Usage) float64 {
+	p, _ := GetModelPrice(model)
+	return p.Cost(u)
+}
@@ -0,0 +1,61 @@
+package forza
+
+import (
+	"math"
+	"testing"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestUsage_TotalAndAdd(t *testing.T) {
+	u := Usage{PromptTokens: 10, CompletionTokens: 5}.Add(Usage{PromptTokens: 1, CompletionTokens: 2})
+	if u.PromptTokens != 11 || u.CompletionTokens != 7 {
+		t.Errorf("unexpected sum: %+v", u)
+	}
+	if u.TotalTokens() != 18 {
+		t.Errorf("expected 18 total tokens, got %d", u.TotalTokens())
+	}
+}
+
+func TestModelPrice_Cost(t *testing.T) {
+	p := ModelPrice{InputPerMillion: 3, OutputPerMillion: 15}
+	got := p.Cost(Usage{PromptTokens: 1_000_000, CompletionTokens: 100_000})
+	if !almostEqual(got, 4.5) {
+		t.Errorf("expected $4.50, got %v", got)
+	}
+}
+
+func TestPricing_CoversPredefinedModels(t *testing.T) {
+	lists := []Models{OpenAIModels, AnthropicModels, GeminiModels}
+	for _, list := range lists {
+		for _, model := range list.ListModels() {
+			if _, ok := GetModelPrice(model); !ok {
+				t.Errorf("missing price for %q", model)
+			}
+		}
+	}
+}
+
+func TestEstimateCost_UnknownModel(t *testing.T) {
+	if got := EstimateCost("unknown-model", Usage{PromptTokens: 1000}); got != 0 {
+		t.Errorf("expected zero cost for unknown model, got %v", got)
+	}
+}
+
+func TestSetModelPrice(t *testing.T) {
+	SetModelPrice("custom-model", ModelPrice{InputPerMillion: 1, OutputPerMillion: 2})
+	got := EstimateCost("custom-model", Usage{PromptTokens: 500_000, CompletionTokens: 500_000})
+	if !almostEqual(got, 1.5) {
+		t.Errorf("expected $1.50, got %v", got)
+	}
+
+	original, _ := GetModelPrice(OpenAIModels.GPT4oMini)
+	defer SetModelPrice(OpenAIModels.GPT4oMini, original)
+
+	SetModelPrice(OpenAIModels.GPT4oMini, ModelPrice{InputPerMillion: 10})
+	if p, _ := GetModelPrice(OpenAIModels.GPT4oMini); p.InputPerMillion != 10 {
+		t.Errorf("expected override to take effect, got %+v", p)
+	}
+}
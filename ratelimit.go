@@ -0,0 +1,282 @@
+package forza
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// RateLimit describes a client-side request budget. A zero value for either
+// field disables that dimension.
+type RateLimit struct {
+	RequestsPerMinute int
+	TokensPerMinute   int
+}
+
+func (r RateLimit) enabled() bool {
+	return r.RequestsPerMinute > 0 || r.TokensPerMinute > 0
+}
+
+// limiters holds one rateLimiter per provider and API key so that every task
+// sharing credentials also shares the same budget. Idle limiters are evicted
+// whenever a new one is added, so the map only grows with the number of
+// credentials in use at the same time.
+var (
+	limitersMu sync.Mutex
+	limiters   = make(map[string]*rateLimiter)
+)
+
+// testHookReserved, if set, runs between looking up a limiter and waiting on
+// it.
+var testHookReserved func()
+
+// limiterFor returns the shared limiter for the config's provider and
+// credentials, or nil if rate limiting is not configured. The most recently
+// seen limits win when several configs share the same key.
+func limiterFor(c *LLMConfig) *rateLimiter {
+	if !c.rateLimit.enabled() {
+		return nil
+	}
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	return lookupLimiter(c)
+}
+
+// lookupLimiter returns the shared limiter for the config, adding it if
+// needed. Caller must hold limitersMu.
+func lookupLimiter(c *LLMConfig) *rateLimiter {
+	key := credentialsKey(c)
+	l, ok := limiters[key]
+	if !ok {
+		for k, other := range limiters {
+			if other.idle() {
+				delete(limiters, k)
+			}
+		}
+		l = newRateLimiter(c.rateLimit)
+		limiters[key] = l
+		return l
+	}
+	l.setLimit(c.rateLimit)
+	return l
+}
+
+// credentialsKey identifies a provider account without keeping the API key
+// in memory as a map key.
+func credentialsKey(c *LLMConfig) string {
+	sum := sha256.Sum256([]byte(c.credentials.endpoint + "\x00" + c.credentials.apiKey))
+	return c.provider + ":" + hex.EncodeToString(sum[:])
+}
+
+// reserveRate blocks until the config's rate limiter admits a request of the
+// estimated size. It returns a nil reservation when no limit is configured.
+// The caller is queued before limitersMu is released, so the limiter cannot
+// be evicted as idle while the caller is on its way to wait on it.
+func reserveRate(ctx context.Context, c *LLMConfig, estimatedTokens int) (*rateReservation, error) {
+	if !c.rateLimit.enabled() {
+		return nil, nil
+	}
+	limitersMu.Lock()
+	l := lookupLimiter(c)
+	turn := l.enqueue()
+	limitersMu.Unlock()
+	if testHookReserved != nil {
+		testHookReserved()
+	}
+	return l.waitTurn(ctx, turn, estimatedTokens)
+}
+
+// estimateTokens gives a rough token count for text, used to size rate limit
+// reservations before the real usage is known.
+func estimateTokens(text string) int {
+	return len(text)/4 + 1
+}
+
+// rateLimiter is a pair of continuously refilling token buckets (requests and
+// tokens) with a FIFO queue of callers.
+type rateLimiter struct {
+	mu       sync.Mutex
+	limit    RateLimit
+	requests float64
+	tokens   float64
+	updated  time.Time
+	queue    []chan struct{}
+	now      func() time.Time
+}
+
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	l := &rateLimiter{now: time.Now}
+	l.limit = limit
+	l.requests = float64(limit.RequestsPerMinute)
+	l.tokens = float64(limit.TokensPerMinute)
+	l.updated = l.now()
+	return l
+}
+
+func (l *rateLimiter) setLimit(limit RateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.limit == limit {
+		return
+	}
+	l.refill()
+	l.limit = limit
+	l.requests = min(l.requests, float64(limit.RequestsPerMinute))
+	l.tokens = min(l.tokens, float64(limit.TokensPerMinute))
+}
+
+// idle reports whether the limiter has no waiters and full buckets, which
+// makes it interchangeable with a new one.
+func (l *rateLimiter) idle() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill()
+	return len(l.queue) == 0 &&
+		l.requests >= float64(l.limit.RequestsPerMinute) &&
+		l.tokens >= float64(l.limit.TokensPerMinute)
+}
+
+// refill adds the budget accrued since the last update. Caller must hold mu.
+func (l *rateLimiter) refill() {
+	now := l.now()
+	elapsed := now.Sub(l.updated).Minutes()
+	l.updated = now
+	if elapsed <= 0 {
+		return
+	}
+	l.requests = min(l.requests+elapsed*float64(l.limit.RequestsPerMinute), float64(l.limit.RequestsPerMinute))
+	l.tokens = min(l.tokens+elapsed*float64(l.limit.TokensPerMinute), float64(l.limit.TokensPerMinute))
+}
+
+// take tries to consume one request and the given tokens. On success it
+// returns the tokens actually taken and a zero delay, otherwise how long to
+// wait before trying again. Caller must hold mu.
+func (l *rateLimiter) take(tokens int) (float64, time.Duration) {
+	l.refill()
+
+	// A reservation larger than the whole bucket could never be satisfied.
+	need := float64(tokens)
+	if l.limit.TokensPerMinute > 0 && need > float64(l.limit.TokensPerMinute) {
+		need = float64(l.limit.TokensPerMinute)
+	}
+
+	var wait time.Duration
+	if l.limit.RequestsPerMinute > 0 && l.requests < 1 {
+		wait = max(wait, minutesToDuration((1-l.requests)/float64(l.limit.RequestsPerMinute)))
+	}
+	if l.limit.TokensPerMinute > 0 && l.tokens < need {
+		wait = max(wait, minutesToDuration((need-l.tokens)/float64(l.limit.TokensPerMinute)))
+	}
+	if wait > 0 {
+		return 0, wait
+	}
+
+	if l.limit.RequestsPerMinute > 0 {
+		l.requests--
+	}
+	if l.limit.TokensPerMinute > 0 {
+		l.tokens -= need
+	} else {
+		need = 0
+	}
+	return need, 0
+}
+
+func minutesToDuration(m float64) time.Duration {
+	d := time.Duration(m * float64(time.Minute))
+	if d < time.Millisecond {
+		d = time.Millisecond
+	}
+	return d
+}
+
+// wait queues the caller and waits for its turn.
+func (l *rateLimiter) wait(ctx context.Context, tokens int) (*rateReservation, error) {
+	return l.waitTurn(ctx, l.enqueue(), tokens)
+}
+
+// enqueue adds a caller to the queue and returns the channel that is closed
+// when it reaches the head.
+func (l *rateLimiter) enqueue() chan struct{} {
+	turn := make(chan struct{})
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.queue = append(l.queue, turn)
+	if len(l.queue) == 1 {
+		close(turn)
+	}
+	return turn
+}
+
+// waitTurn blocks until the queued caller reaches the head of the queue and
+// the buckets hold enough budget, or until ctx is done.
+func (l *rateLimiter) waitTurn(ctx context.Context, turn chan struct{}, tokens int) (*rateReservation, error) {
+	select {
+	case <-turn:
+	case <-ctx.Done():
+		l.leave(turn)
+		return nil, ctx.Err()
+	}
+
+	for {
+		l.mu.Lock()
+		taken, delay := l.take(tokens)
+		l.mu.Unlock()
+		if delay == 0 {
+			l.leave(turn)
+			return &rateReservation{limiter: l, tokens: taken}, nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			l.leave(turn)
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// leave removes a caller from the queue and hands the turn to the next one
+// if the caller was at the head.
+func (l *rateLimiter) leave(turn chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, t := range l.queue {
+		if t != turn {
+			continue
+		}
+		l.queue = append(l.queue[:i], l.queue[i+1:]...)
+		if i == 0 && len(l.queue) > 0 {
+			close(l.queue[0])
+		}
+		return
+	}
+}
+
+// rateReservation records what a request took from the limiter so it can be
+// reconciled once the provider reports the actual usage.
+type rateReservation struct {
+	limiter *rateLimiter
+	tokens  float64
+}
+
+// reconcile returns the difference between the estimated and the actual
+// token usage to the bucket. Pass zero when the request failed without
+// consuming tokens. Safe to call on a nil reservation.
+func (r *rateReservation) reconcile(actualTokens int) {
+	if r == nil {
+		return
+	}
+	l := r.limiter
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.limit.TokensPerMinute <= 0 {
+		return
+	}
+	l.refill()
+	l.tokens = min(l.tokens+r.tokens-float64(actualTokens), float64(l.limit.TokensPerMinute))
+}
@@ -0,0 +1,288 @@
+package forza
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock lets tests control the limiter's notion of time.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestLimiter(limit RateLimit) (*rateLimiter, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	l := newRateLimiter(limit)
+	l.now = clock.Now
+	l.updated = clock.Now()
+	return l, clock
+}
+
+func TestRateLimiter_TakeRequests(t *testing.T) {
+	l, clock := newTestLimiter(RateLimit{RequestsPerMinute: 2})
+
+	for i := 0; i < 2; i++ {
+		if _, delay := l.take(0); delay != 0 {
+			t.Fatalf("request %d: expected no delay, got %v", i+1, delay)
+		}
+	}
+	_, delay := l.take(0)
+	if delay <= 0 || delay > 30*time.Second {
+		t.Errorf("expected delay of up to 30s, got %v", delay)
+	}
+
+	clock.Advance(30 * time.Second)
+	if _, delay := l.take(0); delay != 0 {
+		t.Errorf("expected request to be admitted after refill, got delay %v", delay)
+	}
+}
+
+func TestRateLimiter_TakeTokens(t *testing.T) {
+	l, clock := newTestLimiter(RateLimit{TokensPerMinute: 1000})
+
+	if taken, delay := l.take(800); delay != 0 || taken != 800 {
+		t.Fatalf("expected 800 tokens taken immediately, got %v (delay %v)", taken, delay)
+	}
+	if _, delay := l.take(400); delay == 0 {
+		t.Fatal("expected a delay when tokens are exhausted")
+	}
+
+	clock.Advance(12 * time.Second) // +200 tokens
+	if _, delay := l.take(400); delay != 0 {
+		t.Errorf("expected 400 tokens to be available, got delay %v", delay)
+	}
+}
+
+func TestRateLimiter_OversizedReservationIsClamped(t *testing.T) {
+	l, _ := newTestLimiter(RateLimit{TokensPerMinute: 100})
+
+	taken, delay := l.take(5000)
+	if delay != 0 {
+		t.Fatalf("expected oversized reservation to be admitted, got delay %v", delay)
+	}
+	if taken != 100 {
+		t.Errorf("expected reservation clamped to 100 tokens, got %v", taken)
+	}
+}
+
+func TestRateLimiter_Reconcile(t *testing.T) {
+	l, _ := newTestLimiter(RateLimit{TokensPerMinute: 1000})
+
+	res, err := l.wait(context.Background(), 900)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res.reconcile(100)
+
+	if l.tokens != 900 {
+		t.Errorf("expected 900 tokens after refund, got %v", l.tokens)
+	}
+
+	res, _ = l.wait(context.Background(), 100)
+	res.reconcile(600)
+	if l.tokens != 300 {
+		t.Errorf("expected 300 tokens after charging actual usage, got %v", l.tokens)
+	}
+}
+
+func TestRateReservation_NilIsSafe(t *testing.T) {
+	var r *rateReservation
+	r.reconcile(100) // must not panic
+}
+
+func TestRateLimiter_WaitHonorsContext(t *testing.T) {
+	l, _ := newTestLimiter(RateLimit{RequestsPerMinute: 1})
+	if _, err := l.wait(context.Background(), 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := l.wait(ctx, 0)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if len(l.queue) != 0 {
+		t.Errorf("expected canceled waiter to leave the queue, got %d queued", len(l.queue))
+	}
+}
+
+func TestRateLimiter_FIFO(t *testing.T) {
+	// 1200 RPM admits one request every 50ms, slow enough that all callers
+	// are queued before the first one is admitted.
+	l := newRateLimiter(RateLimit{RequestsPerMinute: 1200})
+	l.requests = 0
+
+	var mu sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := l.wait(context.Background(), 0); err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+		}(i)
+
+		// Wait for goroutine i to join the queue before starting the next one.
+		for {
+			l.mu.Lock()
+			queued := len(l.queue)
+			l.mu.Unlock()
+			if queued == i+1 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	wg.Wait()
+
+	for i, got := range order {
+		if got != i {
+			t.Fatalf("expected FIFO order, got %v", order)
+		}
+	}
+}
+
+func TestLimiterFor_SharedPerProviderAndKey(t *testing.T) {
+	c1 := NewLLMConfig().WithProvider(ProviderAnthropic).WithAnthropicCredentials("shared-key").WithRateLimit(10, 0)
+	c2 := NewLLMConfig().WithProvider(ProviderAnthropic).WithAnthropicCredentials("shared-key").WithRateLimit(10, 0)
+	c3 := NewLLMConfig().WithProvider(ProviderAnthropic).WithAnthropicCredentials("other-key").WithRateLimit(10, 0)
+	c4 := NewLLMConfig().WithProvider(ProviderGemini).WithGeminiCredentials("shared-key").WithRateLimit(10, 0)
+
+	if limiterFor(c1) != limiterFor(c2) {
+		t.Error("expected configs with the same provider and key to share a limiter")
+	}
+	if limiterFor(c1) == limiterFor(c3) {
+		t.Error("expected different API keys to use different limiters")
+	}
+	if limiterFor(c1) == limiterFor(c4) {
+		t.Error("expected different providers to use different limiters")
+	}
+}
+
+func TestLimiterFor_EvictsIdleLimiters(t *testing.T) {
+	idle := NewLLMConfig().WithProvider(ProviderOpenAi).WithOpenAiCredentials("idle-key").WithRateLimit(10, 0)
+	busy := NewLLMConfig().WithProvider(ProviderOpenAi).WithOpenAiCredentials("busy-key").WithRateLimit(10, 0)
+	limiterFor(idle)
+	if _, err := reserveRate(context.Background(), busy, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	limiterFor(NewLLMConfig().WithProvider(ProviderOpenAi).WithOpenAiCredentials("new-key").WithRateLimit(10, 0))
+
+	limitersMu.Lock()
+	_, idleKept := limiters[credentialsKey(idle)]
+	_, busyKept := limiters[credentialsKey(busy)]
+	limitersMu.Unlock()
+	if idleKept || !busyKept {
+		t.Errorf("expected only the idle limiter to be evicted, got idle kept %v, busy kept %v", idleKept, busyKept)
+	}
+}
+
+func TestReserveRate_EvictionDuringWait(t *testing.T) {
+	shared := NewLLMConfig().WithProvider(ProviderOpenAi).WithOpenAiCredentials("contended-key").WithRateLimit(1, 0)
+	testHookReserved = func() {
+		// Another goroutine adds a key, evicting idle limiters, while this
+		// caller is between the lookup and the wait.
+		limiterFor(NewLLMConfig().WithProvider(ProviderOpenAi).WithOpenAiCredentials("evicting-key").WithRateLimit(10, 0))
+	}
+	defer func() { testHookReserved = nil }()
+
+	res, err := reserveRate(context.Background(), shared, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if l := limiterFor(shared); l != res.limiter {
+		t.Fatal("expected the limiter being waited on to survive eviction")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := reserveRate(ctx, shared, 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the next caller to share the spent budget, got %v", err)
+	}
+}
+
+func TestLimiterFor_Disabled(t *testing.T) {
+	c := NewLLMConfig().WithProvider(ProviderOpenAi).WithOpenAiCredentials("key")
+	if limiterFor(c) != nil {
+		t.Error("expected no limiter without a rate limit")
+	}
+	res, err := reserveRate(context.Background(), c, 100)
+	if err != nil || res != nil {
+		t.Errorf("expected nil reservation and error, got %v, %v", res, err)
+	}
+}
+
+func TestLLMConfig_WithRateLimit(t *testing.T) {
+	c := NewLLMConfig().WithRateLimit(50, 40000)
+	if c.rateLimit.RequestsPerMinute != 50 || c.rateLimit.TokensPerMinute != 40000 {
+		t.Errorf("unexpected rate limit: %+v", c.rateLimit)
+	}
+
+	c = NewLLMConfig().WithProvider(ProviderOpenAi).WithModel(OpenAIModels.GPT4o).WithRateLimit(-1, 0)
+	if err := c.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for negative rate limit, got %v", err)
+	}
+}
+
+func TestAnthropic_Completion_ReconcilesRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := anthropicResponse{
+			Type:    "message",
+			Role:    "assistant",
+			Content: []anthropicContentBlock{{Type: "text", Text: "ok"}},
+			Usage:   &anthropicUsage{InputTokens: 30, OutputTokens: 20},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	task := newTestAnthropicTask(server.URL)
+	a := task.(*anthropicProvider)
+	a.config.WithAnthropicCredentials("reconcile-test-key").WithRateLimit(0, 100000)
+	task.WithUserPrompt("hello")
+
+	// Freeze the limiter's clock so no refill happens during the call.
+	l := limiterFor(a.config)
+	frozen := time.Now()
+	l.mu.Lock()
+	l.now = func() time.Time { return frozen }
+	l.updated = frozen
+	l.mu.Unlock()
+
+	if _, err := task.Completion(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if used := 100000 - l.tokens; used != 50 {
+		t.Errorf("expected 50 tokens charged after reconciliation, got %v", used)
+	}
+}
@@ -0,0 +1,165 @@
+package forza
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// reactTool is a registered tool as seen by the ReAct loop.
+type reactTool struct {
+	name        string
+	description string
+	parameters  any
+	builtin     bool
+	fn          toolFunc
+}
+
+// reactStep is one parsed model turn.
+type reactStep struct {
+	thought     string
+	action      string
+	actionInput string
+	finalAnswer string
+	final       bool
+}
+
+// runReAct runs a prompt-based tool loop for models without native function
+// calling. The model is asked to answer in the Thought/Action/Action Input
+// format; each action's result is appended as an Observation and the whole
+// transcript is sent again through complete, which makes a single-turn
+// request without tools. The loop is bounded by defaultMaxToolRounds like
+// the native tool loops.
+func runReAct(ctx context.Context, userPrompt string, tools []reactTool, complete func(ctx context.Context, prompt string) (string, error)) (string, error) {
+	byName := make(map[string]reactTool, len(tools))
+	for _, t := range tools {
+		byName[t.name] = t
+	}
+
+	prompt := reactInstructions(tools) + "\n\nQuestion: " + userPrompt + "\n"
+	var transcript strings.Builder
+	for round := 0; ; round++ {
+		output, err := complete(ctx, prompt+transcript.String())
+		if err != nil {
+			return "", err
+		}
+		step := parseReAct(output)
+		if step.final {
+			return step.finalAnswer, nil
+		}
+		if round == defaultMaxToolRounds {
+			return "", fmt.Errorf("%w: exceeded %d rounds", ErrMaxToolRoundsExceeded, defaultMaxToolRounds)
+		}
+
+		var observation string
+		if tool, ok := byName[step.action]; ok {
+			input := step.actionInput
+			if tool.builtin {
+				input = extractBuiltinToolInput(input)
+			}
+			observation, err = tool.fn(ctx, input)
+			if err != nil {
+				return "", fmt.Errorf("%w: tool %q: %v", ErrToolCallFailed, step.action, err)
+			}
+		} else {
+			observation = fmt.Sprintf("%q is not an available tool. Use one of: %s.", step.action, strings.Join(reactToolNames(tools), ", "))
+		}
+
+		if step.thought != "" {
+			transcript.WriteString("Thought: " + step.thought + "\n")
+		}
+		transcript.WriteString("Action: " + step.action + "\n")
+		transcript.WriteString("Action Input: " + step.actionInput + "\n")
+		transcript.WriteString("Observation: " + observation + "\n")
+	}
+}
+
+// reactInstructions describes the tools and the expected answer format.
+func reactInstructions(tools []reactTool) string {
+	var b strings.Builder
+	b.WriteString("Answer the question below. You can use these tools:\n\n")
+	for _, t := range tools {
+		b.WriteString("- " + t.name + ": " + t.description)
+		if t.builtin {
+			b.WriteString(" Action Input is plain text.")
+		} else if t.parameters != nil {
+			if schema, err := json.Marshal(t.parameters); err == nil {
+				b.WriteString(" Action Input is a JSON object matching this schema: " + string(schema))
+			}
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(`
+Use exactly this format:
+
+Thought: what you need to do next
+Action: the tool to use, one of [` + strings.Join(reactToolNames(tools), ", ") + `]
+Action Input: the input to the tool
+Observation: the tool's result
+
+Thought, Action, Action Input and Observation can repeat. Never write the Observation yourself; stop after Action Input and wait for it. When you know the answer, write:
+
+Thought: I know the final answer
+Final Answer: the answer to the question`)
+	return b.String()
+}
+
+func reactToolNames(tools []reactTool) []string {
+	names := make([]string, len(tools))
+	for i, t := range tools {
+		names[i] = t.name
+	}
+	return names
+}
+
+// parseReAct reads a model turn. Anything from an Observation the model
+// wrote itself onwards is ignored. An Action takes precedence over a Final
+// Answer that follows it; a turn with neither is taken as the final answer.
+func parseReAct(output string) reactStep {
+	if i := strings.Index(output, "\nObservation:"); i >= 0 {
+		output = output[:i]
+	}
+
+	actionAt := reactLabel(output, "Action:")
+	finalAt := reactLabel(output, "Final Answer:")
+	if actionAt < 0 || (finalAt >= 0 && finalAt < actionAt) {
+		if finalAt < 0 {
+			return reactStep{final: true, finalAnswer: strings.TrimSpace(output)}
+		}
+		return reactStep{final: true, finalAnswer: strings.TrimSpace(output[finalAt+len("Final Answer:"):])}
+	}
+
+	var step reactStep
+	if thoughtAt := reactLabel(output, "Thought:"); thoughtAt >= 0 && thoughtAt < actionAt {
+		step.thought = strings.TrimSpace(output[thoughtAt+len("Thought:") : actionAt])
+	}
+	rest := output[actionAt+len("Action:"):]
+	if inputAt := reactLabel(rest, "Action Input:"); inputAt >= 0 {
+		step.action = strings.TrimSpace(rest[:inputAt])
+		input := rest[inputAt+len("Action Input:"):]
+		if finalAt := reactLabel(input, "Final Answer:"); finalAt >= 0 {
+			input = input[:finalAt]
+		}
+		step.actionInput = strings.Trim(stripCodeFence(input), `"`)
+	} else {
+		step.action, _, _ = strings.Cut(strings.TrimSpace(rest), "\n")
+	}
+	step.action = strings.Trim(strings.TrimSpace(step.action), "`[]")
+	return step
+}
+
+// reactLabel returns the index of label at the start of a line in s, or -1.
+func reactLabel(s, label string) int {
+	for offset := 0; ; {
+		i := strings.Index(s[offset:], label)
+		if i < 0 {
+			return -1
+		}
+		i += offset
+		if i == 0 || s[i-1] == '\n' {
+			return i
+		}
+		offset = i + len(label)
+	}
+}
@@ -0,0 +1,148 @@
+package forza
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+func TestParseReAct(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   reactStep
+	}{
+		{
+			"action",
+			"Thought: I need the weather\nAction: get_weather\nAction Input: {\"city\": \"Paris\"}",
+			reactStep{thought: "I need the weather", action: "get_weather", actionInput: `{"city": "Paris"}`},
+		},
+		{
+			"ignores an invented observation",
+			"Action: search\nAction Input: \"go generics\"\nObservation: made up\nFinal Answer: wrong",
+			reactStep{action: "search", actionInput: "go generics"},
+		},
+		{
+			"final answer",
+			"Thought: I know the final answer\nFinal Answer: It is sunny.\nHave a nice day.",
+			reactStep{final: true, finalAnswer: "It is sunny.\nHave a nice day."},
+		},
+		{
+			"plain answer",
+			"  Just an answer.  ",
+			reactStep{final: true, finalAnswer: "Just an answer."},
+		},
+		{
+			"label inside text is not a label",
+			"Thought: the word Action: appears here\nAction: `lookup`\nAction Input: ```json\n{\"q\": 1}\n```",
+			reactStep{thought: "the word Action: appears here", action: "lookup", actionInput: `{"q": 1}`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseReAct(tt.output); got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+// reactServer plays a tool-incapable model: it calls get_weather once, then
+// answers with the observation it was given.
+func reactServer(t *testing.T, requests *[]openai.ChatCompletionRequest, firstAction string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openai.ChatCompletionRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		*requests = append(*requests, req)
+
+		prompt := req.Messages[len(req.Messages)-1].Content
+		answer := firstAction
+		_, transcript, _ := strings.Cut(prompt, "\nQuestion: ")
+		if _, observation, ok := strings.Cut(transcript, "\nObservation: "); ok {
+			answer = "Thought: I know the final answer\nFinal Answer: " + strings.TrimSpace(observation)
+		}
+		json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: answer}}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestPhi3Task(serverURL string) LLMAgent {
+	config := NewLLMConfig().WithProvider(ProviderOllama).WithModel(OllamaModels.Phi3).WithOllamaCredentials(serverURL + "/v1")
+	task, _ := NewAgent().WithRole("Tester").WithBackstory("backstory").WithGoal("goal").NewLLMTask(config)
+	return task
+}
+
+func TestReAct_ToolIncapableModel(t *testing.T) {
+	var requests []openai.ChatCompletionRequest
+	server := reactServer(t, &requests, "Thought: I should check\nAction: get_weather\nAction Input: {\"city\": \"Paris\"}")
+
+	task := newTestPhi3Task(server.URL)
+	var gotInput string
+	task.AddCustomTools("get_weather", "current weather for a city", NewFunction(WithProperty("city", "city name", true)), func(input string) (string, error) {
+		gotInput = input
+		return "Sunny, 22C", nil
+	})
+	task.WithUserPrompt("What's the weather in Paris?")
+
+	answer, err := task.Completion(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if answer != "Sunny, 22C" || gotInput != `{"city": "Paris"}` {
+		t.Errorf("unexpected answer %q (tool input %q)", answer, gotInput)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+	if len(requests[0].Tools) != 0 {
+		t.Error("expected no native tools for a tool-incapable model")
+	}
+	first := requests[0].Messages[len(requests[0].Messages)-1].Content
+	if !strings.Contains(first, "- get_weather: current weather for a city") || !strings.Contains(first, "Question: What's the weather in Paris?") {
+		t.Errorf("expected the tools and question in the prompt, got %q", first)
+	}
+}
+
+func TestReAct_BuiltinToolAndUnknownTool(t *testing.T) {
+	var requests []openai.ChatCompletionRequest
+	server := reactServer(t, &requests, "Action: probe\nAction Input: hello")
+
+	task := newTestPhi3Task(server.URL)
+	task.WithTools(&funcTool{name: "probe", fn: func(ctx context.Context, input string) (string, error) {
+		return "probed " + input, nil
+	}})
+	task.WithUserPrompt("go")
+	if answer, err := task.Completion(context.Background()); err != nil || answer != "probed hello" {
+		t.Errorf("unexpected answer %q, %v", answer, err)
+	}
+
+	server = reactServer(t, &requests, "Action: nope\nAction Input: x")
+	task = newTestPhi3Task(server.URL)
+	task.WithTools(&funcTool{name: "probe"})
+	task.WithUserPrompt("go")
+	if answer, _ := task.Completion(context.Background()); !strings.Contains(answer, `"nope" is not an available tool. Use one of: probe.`) {
+		t.Errorf("expected the unknown tool reported as an observation, got %q", answer)
+	}
+}
+
+func TestReAct_RoundLimit(t *testing.T) {
+	calls := 0
+	_, err := runReAct(context.Background(), "q", []reactTool{{name: "loop", fn: func(context.Context, string) (string, error) { return "again", nil }}},
+		func(ctx context.Context, prompt string) (string, error) {
+			calls++
+			return "Action: loop\nAction Input: x", nil
+		})
+	if !errors.Is(err, ErrMaxToolRoundsExceeded) || calls != defaultMaxToolRounds+1 {
+		t.Errorf("expected the round limit after %d calls, got %v after %d", defaultMaxToolRounds+1, err, calls)
+	}
+}
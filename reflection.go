@@ -0,0 +1,216 @@
+package forza
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+const defaultReflectionIterations = 3
+
+// Critique is a critic's verdict on one draft.
+type Critique struct {
+	Iteration int    `json:"iteration"`
+	Draft     string `json:"draft"`
+	Approved  bool   `json:"approved"`
+	Feedback  string `json:"feedback"`
+}
+
+// ReflectionResult is the outcome of a Reflection run: the final draft,
+// whether the critic approved it, and every critique in order.
+type ReflectionResult struct {
+	Draft     string
+	Approved  bool
+	Critiques []Critique
+}
+
+// Reflection runs a "draft, critique, revise" loop: a generator agent writes
+// a draft, a critic reviews it, and the generator revises it with the
+// critic's feedback until the critic approves or a limit is reached.
+//
+// The critic is either an LLMAgent (WithCritic) or a Go function
+// (WithValidator). When the context carries a CostTracker, drafts and
+// critiques are tracked under children named "draft N" and "critique N".
+type Reflection struct {
+	generator     LLMAgent
+	critic        func(ctx context.Context, draft string) (approved bool, feedback string, err error)
+	criticErr     error // set when the critic was set to nil
+	maxIterations int
+	budget        float64
+	logger        *slog.Logger
+}
+
+// NewReflection creates a Reflection around a generator agent. The generator
+// keeps its own user prompt; revisions pass the previous draft and the
+// critic's feedback as context.
+func NewReflection(generator LLMAgent) *Reflection {
+	return &Reflection{generator: generator, maxIterations: defaultReflectionIterations}
+}
+
+// WithCritic makes agent review each draft. The agent's user prompt is
+// replaced with review instructions asking for a JSON object with "approved"
+// and "feedback" fields, so the agent should be dedicated to the loop; its
+// role, backstory and goal describe what it checks for. A nil agent is
+// reported by Run with ErrNilTask.
+func (r *Reflection) WithCritic(agent LLMAgent) *Reflection {
+	if agent == nil {
+		r.critic, r.criticErr = nil, fmt.Errorf("%w: reflection critic is nil", ErrNilTask)
+		return r
+	}
+	r.criticErr = nil
+	agent.WithUserPrompt(`Review the draft given as context. If it fully meets your standards, approve it. Otherwise explain concisely what must change. ` +
+		`Answer only with a JSON object of the form {"approved": true or false, "feedback": "<what to change, or empty when approved>"}.`)
+	r.critic = func(ctx context.Context, draft string) (bool, string, error) {
+		answer, err := agent.Completion(ctx, draft)
+		if err != nil {
+			return false, "", err
+		}
+		var verdict struct {
+			Approved bool   `json:"approved"`
+			Feedback string `json:"feedback"`
+		}
+		if err := json.Unmarshal([]byte(stripCodeFence(answer)), &verdict); err != nil {
+			return false, "", fmt.Errorf("%w: critic answer: %v", ErrInvalidOutput, err)
+		}
+		return verdict.Approved, verdict.Feedback, nil
+	}
+	return r
+}
+
+// WithValidator makes fn review each draft: a nil error approves it, and
+// any other error's message is the feedback for the next revision. A nil fn
+// is reported by Run with ErrNilTask.
+func (r *Reflection) WithValidator(fn func(ctx context.Context, draft string) error) *Reflection {
+	if fn == nil {
+		r.critic, r.criticErr = nil, fmt.Errorf("%w: reflection validator is nil", ErrNilTask)
+		return r
+	}
+	r.criticErr = nil
+	r.critic = func(ctx context.Context, draft string) (bool, string, error) {
+		if err := fn(ctx, draft); err != nil {
+			return false, err.Error(), nil
+		}
+		return true, "", nil
+	}
+	return r
+}
+
+// WithMaxIterations sets how many drafts are written at most. Default is 3.
+func (r *Reflection) WithMaxIterations(n int) *Reflection {
+	r.maxIterations = n
+	return r
+}
+
+// WithBudget limits the loop's spend in USD. The loop runs under a child of
+// the context's CostTracker, or a new tracker if there is none, and stops
+// with ErrBudgetExceeded once the limit is reached.
+func (r *Reflection) WithBudget(usd float64) *Reflection {
+	r.budget = usd
+	return r
+}
+
+// WithLogger sets an optional logger for the loop. If nil, no logging occurs.
+func (r *Reflection) WithLogger(l *slog.Logger) *Reflection {
+	r.logger = l
+	return r
+}
+
+// Task returns the loop as a TaskChainFn that produces the final draft, for
+// use in a Pipeline or DAG. Its context argument, if any, is the input.
+func (r *Reflection) Task() TaskChainFn {
+	return func(ctx context.Context, params ...string) (string, error) {
+		if len(params) > 1 {
+			return "", ErrTooManyArgs
+		}
+		var input string
+		if len(params) == 1 {
+			input = params[0]
+		}
+		result, err := r.Run(ctx, input)
+		return result.Draft, err
+	}
+}
+
+// Run writes and revises drafts until the critic approves one or the
+// iteration limit is reached; an unapproved final draft is not an error.
+// If a completion fails or the budget runs out, Run returns the result so
+// far, with the latest draft, together with the error. The optional input
+// is passed to the generator as context.
+func (r *Reflection) Run(ctx context.Context, input string) (ReflectionResult, error) {
+	var result ReflectionResult
+	if r.generator == nil {
+		return result, fmt.Errorf("%w: reflection has no generator", ErrNilTask)
+	}
+	if r.criticErr != nil {
+		return result, r.criticErr
+	}
+	if r.critic == nil {
+		return result, fmt.Errorf("%w: reflection has no critic (use WithCritic() or WithValidator())", ErrInvalidConfig)
+	}
+	if r.maxIterations < 1 {
+		return result, fmt.Errorf("%w: max iterations must be at least 1", ErrInvalidConfig)
+	}
+	if r.budget > 0 {
+		tracker := CostTrackerFromContext(ctx)
+		if tracker == nil {
+			tracker = NewCostTracker()
+		} else {
+			tracker = tracker.Child("reflection")
+		}
+		ctx = ContextWithCostTracker(ctx, tracker.WithBudget(r.budget))
+	}
+
+	for i := 1; i <= r.maxIterations; i++ {
+		draftCtx := namedTaskContext(ctx, fmt.Sprintf("draft %d", i))
+		var draft string
+		var err error
+		if revision := revisionContext(input, result.Critiques); revision != "" {
+			draft, err = r.generator.Completion(draftCtx, revision)
+		} else {
+			draft, err = r.generator.Completion(draftCtx)
+		}
+		if err != nil {
+			return result, fmt.Errorf("draft %d: %w", i, err)
+		}
+		result.Draft = draft
+
+		approved, feedback, err := r.critic(internalTaskContext(ctx, fmt.Sprintf("critique %d", i)), draft)
+		if err != nil {
+			return result, fmt.Errorf("critique %d: %w", i, err)
+		}
+		result.Critiques = append(result.Critiques, Critique{Iteration: i, Draft: draft, Approved: approved, Feedback: feedback})
+		r.logDebug("reflection iteration", "iteration", i, "approved", approved)
+		if approved {
+			result.Approved = true
+			return result, nil
+		}
+	}
+	return result, nil
+}
+
+// revisionContext builds the generator's context: the input, followed by the
+// latest draft and its feedback once there is one.
+func revisionContext(input string, critiques []Critique) string {
+	if len(critiques) == 0 {
+		return input
+	}
+	last := critiques[len(critiques)-1]
+	var parts []string
+	if input != "" {
+		parts = append(parts, input)
+	}
+	parts = append(parts,
+		"Your previous draft:\n"+last.Draft,
+		"Reviewer feedback:\n"+last.Feedback,
+		"Write a revised draft that addresses the feedback.",
+	)
+	return strings.Join(parts, "\n\n")
+}
+
+func (r *Reflection) logDebug(msg string, args ...any) {
+	if r.logger != nil {
+		r.logger.Debug(msg, args...)
+	}
+}
@@ -0,0 +1,112 @@
+package forza
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// draftWriter numbers its drafts and records the context of each call.
+func draftWriter() (*stubAgent, *[]string) {
+	var contexts []string
+	a := &stubAgent{}
+	a.complete = func(ctx context.Context, params ...string) (string, error) {
+		if err := checkBudget(ctx); err != nil {
+			return "", err
+		}
+		recordUsage(ctx, OpenAIModels.GPT4o, Usage{PromptTokens: 1000, CompletionTokens: 1000})
+		contexts = append(contexts, strings.Join(params, ""))
+		return fmt.Sprintf("draft %d", a.calls), nil
+	}
+	return a, &contexts
+}
+
+func TestReflection_CriticApproves(t *testing.T) {
+	generator, contexts := draftWriter()
+	critic := &stubAgent{}
+	critic.complete = func(ctx context.Context, params ...string) (string, error) {
+		if params[0] == "draft 2" {
+			return `{"approved": true, "feedback": ""}`, nil
+		}
+		return `{"approved": false, "feedback": "too long"}`, nil
+	}
+
+	result, err := NewReflection(generator).WithCritic(critic).Run(context.Background(), "topic: Go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Approved || result.Draft != "draft 2" || len(result.Critiques) != 2 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if result.Critiques[0] != (Critique{Iteration: 1, Draft: "draft 1", Feedback: "too long"}) {
+		t.Errorf("unexpected first critique %+v", result.Critiques[0])
+	}
+	if (*contexts)[0] != "topic: Go" {
+		t.Errorf("expected the input as the first context, got %q", (*contexts)[0])
+	}
+	revision := (*contexts)[1]
+	if !strings.HasPrefix(revision, "topic: Go") || !strings.Contains(revision, "draft 1") || !strings.Contains(revision, "too long") {
+		t.Errorf("expected the draft and feedback in the revision context, got %q", revision)
+	}
+	if !strings.Contains(critic.prompt, `"approved"`) {
+		t.Errorf("expected JSON review instructions, got %q", critic.prompt)
+	}
+}
+
+func TestReflection_ValidatorAndMaxIterations(t *testing.T) {
+	generator, _ := draftWriter()
+	result, err := NewReflection(generator).
+		WithValidator(func(ctx context.Context, draft string) error {
+			return errors.New("missing a conclusion")
+		}).
+		WithMaxIterations(2).
+		Task()(context.Background())
+	if err != nil || result != "draft 2" {
+		t.Errorf("expected the last draft without error, got %q, %v", result, err)
+	}
+	if generator.calls != 2 {
+		t.Errorf("expected 2 drafts, got %d", generator.calls)
+	}
+}
+
+func TestReflection_Budget(t *testing.T) {
+	generator, _ := draftWriter()
+	tracker := NewCostTracker()
+	ctx := ContextWithCostTracker(context.Background(), tracker)
+
+	result, err := NewReflection(generator).
+		WithValidator(func(context.Context, string) error { return errors.New("again") }).
+		WithMaxIterations(10).
+		WithBudget(0.01).
+		Run(ctx, "")
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("expected ErrBudgetExceeded, got %v", err)
+	}
+	if result.Draft != "draft 1" || generator.calls != 2 {
+		t.Errorf("expected to stop after the budget ran out, got %+v after %d calls", result, generator.calls)
+	}
+	if children := tracker.Children(); len(children) != 1 || children[0].Name() != "reflection" {
+		t.Errorf("expected a reflection child tracker, got %v", children)
+	}
+}
+
+func TestReflection_Errors(t *testing.T) {
+	generator, _ := draftWriter()
+	if _, err := NewReflection(generator).Run(context.Background(), ""); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected a missing critic error, got %v", err)
+	}
+	if _, err := NewReflection(generator).WithCritic(nil).Run(context.Background(), ""); !errors.Is(err, ErrNilTask) {
+		t.Errorf("expected ErrNilTask for a nil critic, got %v", err)
+	}
+	if _, err := NewReflection(generator).WithValidator(nil).Run(context.Background(), ""); !errors.Is(err, ErrNilTask) {
+		t.Errorf("expected ErrNilTask for a nil validator, got %v", err)
+	}
+
+	critic := &stubAgent{complete: func(context.Context, ...string) (string, error) { return "looks good!", nil }}
+	result, err := NewReflection(generator).WithCritic(critic).Run(context.Background(), "")
+	if !errors.Is(err, ErrInvalidOutput) || result.Draft == "" {
+		t.Errorf("expected ErrInvalidOutput with the draft kept, got %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,221 @@
+package forza
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// Route is a named branch of a Router. Description tells an LLM classifier
+// when to pick it. Task receives the router's input as its context argument;
+// a sub-pipeline can be routed to by composing it into a single task, for
+// example with ToChainFn and Then.
+type Route struct {
+	Name        string
+	Description string
+	Task        TaskChainFn
+}
+
+// RouteDecision records which route a Router chose and why.
+type RouteDecision struct {
+	Route     string `json:"route"`
+	Rationale string `json:"rationale"`
+}
+
+// Router sends its input to one of several named routes, chosen either by an
+// LLM classifier (WithLLM) or by a Go function (WithPredicate). Use Task to
+// place it in a Pipeline like any other task.
+type Router struct {
+	routes       []Route
+	choose       func(ctx context.Context, input string) (RouteDecision, error)
+	chooserErr   error // set when the chooser was set to nil
+	defaultRoute string
+	logger       *slog.Logger
+
+	mu   sync.Mutex
+	last RouteDecision
+}
+
+// CreateRouter returns a Router over the given routes that logs its decisions
+// with the pipeline's logger. Set how it chooses with WithLLM or WithPredicate.
+func (p *Pipeline) CreateRouter(routes ...Route) *Router {
+	return &Router{routes: routes, logger: p.logger}
+}
+
+// WithLLM makes the router ask agent to classify the input. The agent's user
+// prompt is replaced with instructions listing the routes and asking for a
+// JSON object with "route" and "rationale" fields, so the agent should be
+// dedicated to the router. A nil agent is reported by Run with ErrNilTask.
+func (r *Router) WithLLM(agent LLMAgent) *Router {
+	if agent == nil {
+		r.choose, r.chooserErr = nil, fmt.Errorf("%w: router classifier is nil", ErrNilTask)
+		return r
+	}
+	r.chooserErr = nil
+	agent.WithUserPrompt(r.classifierPrompt())
+	r.choose = func(ctx context.Context, input string) (RouteDecision, error) {
+		var params []string
+		if input != "" {
+			params = []string{input}
+		}
+		answer, err := agent.Completion(ctx, params...)
+		if err != nil {
+			return RouteDecision{}, err
+		}
+		var d RouteDecision
+		if err := json.Unmarshal([]byte(stripCodeFence(answer)), &d); err != nil {
+			return RouteDecision{}, fmt.Errorf("%w: router answer: %v", ErrInvalidOutput, err)
+		}
+		return d, nil
+	}
+	return r
+}
+
+// WithPredicate makes the router call fn to pick a route by name. The
+// recorded rationale says the route was chosen by a predicate. A nil fn is
+// reported by Run with ErrNilTask.
+func (r *Router) WithPredicate(fn func(ctx context.Context, input string) (string, error)) *Router {
+	if fn == nil {
+		r.choose, r.chooserErr = nil, fmt.Errorf("%w: router predicate is nil", ErrNilTask)
+		return r
+	}
+	r.chooserErr = nil
+	r.choose = func(ctx context.Context, input string) (RouteDecision, error) {
+		name, err := fn(ctx, input)
+		if err != nil {
+			return RouteDecision{}, err
+		}
+		return RouteDecision{Route: name, Rationale: "chosen by predicate"}, nil
+	}
+	return r
+}
+
+// WithDefault sets the route used when the chooser fails or names a route
+// that doesn't exist. Without one, those cases are errors.
+func (r *Router) WithDefault(name string) *Router {
+	r.defaultRoute = name
+	return r
+}
+
+// LastDecision returns the most recent routing decision.
+func (r *Router) LastDecision() RouteDecision {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.last
+}
+
+// Task returns the router as a TaskChainFn for AddTasks, CreateChain or a DAG.
+// Its context argument, if any, is the input to route.
+func (r *Router) Task() TaskChainFn {
+	return func(ctx context.Context, params ...string) (string, error) {
+		if len(params) > 1 {
+			return "", ErrTooManyArgs
+		}
+		var input string
+		if len(params) == 1 {
+			input = params[0]
+		}
+		result, _, err := r.Run(ctx, input)
+		return result, err
+	}
+}
+
+// Run chooses a route for input, runs it and returns its result together
+// with the decision. The decision is returned, and recorded for
+// LastDecision, even when the route's task fails.
+func (r *Router) Run(ctx context.Context, input string) (string, RouteDecision, error) {
+	if err := r.validate(); err != nil {
+		return "", RouteDecision{}, err
+	}
+
+	d, err := r.choose(internalTaskContext(ctx, "router"), input)
+	if err != nil {
+		if r.defaultRoute == "" || ctx.Err() != nil {
+			return "", RouteDecision{}, fmt.Errorf("routing failed: %w", err)
+		}
+		d = RouteDecision{Route: r.defaultRoute, Rationale: fmt.Sprintf("default route: routing failed: %v", err)}
+	}
+	route, ok := r.route(d.Route)
+	if !ok {
+		if r.defaultRoute == "" {
+			return "", d, fmt.Errorf("%w: %q", ErrUnknownRoute, d.Route)
+		}
+		d = RouteDecision{Route: r.defaultRoute, Rationale: fmt.Sprintf("default route: unknown route %q", d.Route)}
+		route, _ = r.route(r.defaultRoute)
+	}
+
+	r.mu.Lock()
+	r.last = d
+	r.mu.Unlock()
+	if r.logger != nil {
+		r.logger.Debug("route chosen", "route", d.Route, "rationale", d.Rationale)
+	}
+
+	routeCtx := namedTaskContext(ctx, route.Name)
+	var result string
+	if input == "" {
+		result, err = route.Task(routeCtx)
+	} else {
+		result, err = route.Task(routeCtx, input)
+	}
+	if err != nil {
+		return "", d, fmt.Errorf("route %q: %w", route.Name, err)
+	}
+	return result, d, nil
+}
+
+func (r *Router) route(name string) (Route, bool) {
+	for _, route := range r.routes {
+		if route.Name == name {
+			return route, true
+		}
+	}
+	return Route{}, false
+}
+
+func (r *Router) validate() error {
+	if len(r.routes) == 0 {
+		return fmt.Errorf("%w: at least one route is required", ErrInvalidRouter)
+	}
+	if r.chooserErr != nil {
+		return r.chooserErr
+	}
+	if r.choose == nil {
+		return fmt.Errorf("%w: no chooser set (use WithLLM() or WithPredicate())", ErrInvalidRouter)
+	}
+	seen := make(map[string]bool, len(r.routes))
+	for i, route := range r.routes {
+		if route.Name == "" {
+			return fmt.Errorf("%w: route %d has no name", ErrInvalidRouter, i+1)
+		}
+		if seen[route.Name] {
+			return fmt.Errorf("%w: duplicate route %q", ErrInvalidRouter, route.Name)
+		}
+		if route.Task == nil {
+			return fmt.Errorf("%w: route %q", ErrNilTask, route.Name)
+		}
+		seen[route.Name] = true
+	}
+	if r.defaultRoute != "" && !seen[r.defaultRoute] {
+		return fmt.Errorf("%w: default route %q is not defined", ErrInvalidRouter, r.defaultRoute)
+	}
+	return nil
+}
+
+// classifierPrompt asks the model to pick one of the routes.
+func (r *Router) classifierPrompt() string {
+	var b strings.Builder
+	b.WriteString("Choose the route that best fits the input given as context. The routes are:\n")
+	for _, route := range r.routes {
+		b.WriteString("- " + route.Name)
+		if route.Description != "" {
+			b.WriteString(": " + route.Description)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(`Answer only with a JSON object of the form {"route": "<route name>", "rationale": "<one sentence explaining the choice>"}.`)
+	return b.String()
+}
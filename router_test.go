@@ -0,0 +1,102 @@
+package forza
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func ticketRoutes() []Route {
+	return []Route{
+		{Name: "billing", Description: "invoices and refunds", Task: mockTask("billing")},
+		{Name: "tech", Description: "bugs and outages", Task: mockTask("tech")},
+		{Name: "escalate", Description: "angry customers", Task: mockTask("escalated")},
+	}
+}
+
+func TestRouter_LLM(t *testing.T) {
+	classifier := &stubAgent{complete: func(ctx context.Context, params ...string) (string, error) {
+		return "```json\n{\"route\": \"billing\", \"rationale\": \"mentions a refund\"}\n```", nil
+	}}
+	router := NewPipeline().CreateRouter(ticketRoutes()...).WithLLM(classifier)
+	if !strings.Contains(classifier.prompt, "- billing: invoices and refunds") {
+		t.Errorf("expected the routes in the classifier prompt, got %q", classifier.prompt)
+	}
+
+	result, d, err := router.Run(context.Background(), "I want a refund")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "billing [ctx:I want a refund]" {
+		t.Errorf("expected the input to reach the route, got %q", result)
+	}
+	if d != (RouteDecision{Route: "billing", Rationale: "mentions a refund"}) || router.LastDecision() != d {
+		t.Errorf("unexpected decision %+v", d)
+	}
+}
+
+func TestRouter_PredicateInChain(t *testing.T) {
+	router := NewPipeline().CreateRouter(ticketRoutes()...).WithPredicate(func(ctx context.Context, input string) (string, error) {
+		if strings.Contains(input, "crash") {
+			return "tech", nil
+		}
+		return "escalate", nil
+	})
+
+	chain := NewPipeline().CreateChain(mockTask("app crash"), router.Task())
+	result, err := chain(context.Background())
+	if err != nil || result != "tech [ctx:app crash]" {
+		t.Errorf("unexpected result %q, %v", result, err)
+	}
+	if d := router.LastDecision(); d.Route != "tech" || d.Rationale != "chosen by predicate" {
+		t.Errorf("unexpected decision %+v", d)
+	}
+}
+
+func TestRouter_UnknownRouteAndDefault(t *testing.T) {
+	pick := func(name string) func(context.Context, string) (string, error) {
+		return func(context.Context, string) (string, error) { return name, nil }
+	}
+
+	router := NewPipeline().CreateRouter(ticketRoutes()...).WithPredicate(pick("sales"))
+	if _, _, err := router.Run(context.Background(), "x"); !errors.Is(err, ErrUnknownRoute) {
+		t.Errorf("expected ErrUnknownRoute, got %v", err)
+	}
+
+	router.WithDefault("escalate")
+	result, d, err := router.Run(context.Background(), "x")
+	if err != nil || result != "escalated [ctx:x]" || !strings.Contains(d.Rationale, `unknown route "sales"`) {
+		t.Errorf("expected the default route, got %q, %+v, %v", result, d, err)
+	}
+
+	failing := &stubAgent{complete: func(context.Context, ...string) (string, error) { return "no json here", nil }}
+	router = NewPipeline().CreateRouter(ticketRoutes()...).WithLLM(failing)
+	if _, _, err := router.Run(context.Background(), "x"); !errors.Is(err, ErrInvalidOutput) {
+		t.Errorf("expected ErrInvalidOutput, got %v", err)
+	}
+}
+
+func TestRouter_Validate(t *testing.T) {
+	pick := func(context.Context, string) (string, error) { return "a", nil }
+	tests := []struct {
+		name   string
+		router *Router
+		want   error
+	}{
+		{"no routes", NewPipeline().CreateRouter().WithPredicate(pick), ErrInvalidRouter},
+		{"no chooser", NewPipeline().CreateRouter(Route{Name: "a", Task: mockTask("a")}), ErrInvalidRouter},
+		{"nil classifier", NewPipeline().CreateRouter(Route{Name: "a", Task: mockTask("a")}).WithLLM(nil), ErrNilTask},
+		{"nil predicate", NewPipeline().CreateRouter(Route{Name: "a", Task: mockTask("a")}).WithPredicate(nil), ErrNilTask},
+		{"duplicate", NewPipeline().CreateRouter(Route{Name: "a", Task: mockTask("a")}, Route{Name: "a", Task: mockTask("b")}).WithPredicate(pick), ErrInvalidRouter},
+		{"nil task", NewPipeline().CreateRouter(Route{Name: "a"}).WithPredicate(pick), ErrNilTask},
+		{"unknown default", NewPipeline().CreateRouter(Route{Name: "a", Task: mockTask("a")}).WithPredicate(pick).WithDefault("b"), ErrInvalidRouter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := tt.router.Run(context.Background(), "x"); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
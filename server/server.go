@@ -0,0 +1,425 @@
+// Package server exposes forza agents and pipelines over an OpenAI-compatible
+// HTTP API, so that any OpenAI SDK can call them by pointing its base URL at
+// the server.
+//
+// The "model" of a chat completion request names a registered agent or
+// pipeline. The last message is the prompt and the earlier messages are
+// passed along as context. Each request gets a fresh task, so a single
+// Server can serve concurrent requests.
+//
+// Completion errors are logged in full (see WithLogger) but answered with a
+// generic message for their kind, since provider errors can include upstream
+// response bodies and endpoints. Streamed requests receive the whole answer
+// in one chunk once the completion finishes; there are no partial deltas.
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/vitoraguila/forza"
+	"github.com/vitoraguila/forza/tools"
+)
+
+// DefaultMaxBodyBytes is the default limit on request body size.
+const DefaultMaxBodyBytes = 1 << 20 // 1 MB
+
+// keepAliveInterval is how often an SSE comment is sent while a streamed
+// completion is still running.
+const keepAliveInterval = 15 * time.Second
+
+var (
+	ErrDuplicateModel = errors.New("model name is already registered")
+	ErrEmptyModelName = errors.New("model name must not be empty")
+)
+
+// CompletionFunc answers one chat request. prompt is the content of the last
+// message and history renders the earlier messages ("" if there are none).
+type CompletionFunc func(ctx context.Context, prompt, history string) (string, error)
+
+// AgentCompletion serves an agent. A new task is created for every request;
+// the config is validated once up front.
+func AgentCompletion(agent *forza.Agent, config *forza.LLMConfig, t ...tools.Tool) (CompletionFunc, error) {
+	if _, err := agent.NewLLMTask(config); err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, prompt, history string) (string, error) {
+		task, err := agent.NewLLMTask(config)
+		if err != nil {
+			return "", err
+		}
+		task.WithUserPrompt(prompt)
+		if len(t) > 0 {
+			task.WithTools(t...)
+		}
+		if history == "" {
+			return task.Completion(ctx)
+		}
+		return task.Completion(ctx, history)
+	}, nil
+}
+
+// DefinitionCompletion serves a declarative pipeline. The conversation is
+// given to the pipeline as input (see forza.DefinedPipeline.Run) and the
+// results of all tasks are joined with blank lines.
+func DefinitionCompletion(def *forza.Definition, t ...tools.Tool) (CompletionFunc, error) {
+	if _, err := def.Build(t...); err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, prompt, history string) (string, error) {
+		pipeline, err := def.Build(t...)
+		if err != nil {
+			return "", err
+		}
+		input := prompt
+		if history != "" {
+			input = history + "\nuser: " + prompt
+		}
+		results, err := pipeline.Run(ctx, input)
+		if err != nil {
+			return "", err
+		}
+		return strings.Join(results, "\n\n"), nil
+	}, nil
+}
+
+// Server is an http.Handler serving /v1/chat/completions and /v1/models.
+type Server struct {
+	mu     sync.RWMutex
+	models map[string]CompletionFunc
+
+	apiKeys      [][]byte
+	maxBodyBytes int64
+	logger       *slog.Logger
+	created      int64
+	mux          *http.ServeMux
+}
+
+// New creates a Server with no models and no authentication.
+func New() *Server {
+	s := &Server{
+		models:       make(map[string]CompletionFunc),
+		maxBodyBytes: DefaultMaxBodyBytes,
+		created:      time.Now().Unix(),
+		mux:          http.NewServeMux(),
+	}
+	s.mux.HandleFunc("/v1/chat/completions", s.handleChatCompletions)
+	s.mux.HandleFunc("/v1/models", s.handleModels)
+	return s
+}
+
+// WithAPIKeys requires requests to carry one of the keys as a bearer token.
+func (s *Server) WithAPIKeys(keys ...string) *Server {
+	for _, k := range keys {
+		s.apiKeys = append(s.apiKeys, []byte(k))
+	}
+	return s
+}
+
+// WithMaxBodyBytes limits the size of request bodies (default: 1 MB).
+func (s *Server) WithMaxBodyBytes(n int64) *Server {
+	s.maxBodyBytes = n
+	return s
+}
+
+// WithLogger sets an optional logger for failed requests. If nil, no logging occurs.
+func (s *Server) WithLogger(l *slog.Logger) *Server {
+	s.logger = l
+	return s
+}
+
+// Handle registers fn under a model name.
+func (s *Server) Handle(name string, fn CompletionFunc) error {
+	if name == "" {
+		return ErrEmptyModelName
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.models[name]; exists {
+		return fmt.Errorf("%w: %q", ErrDuplicateModel, name)
+	}
+	s.models[name] = fn
+	return nil
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "invalid_request_error", "invalid_api_key", "Incorrect API key provided.")
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	if len(s.apiKeys) == 0 {
+		return true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	for _, k := range s.apiKeys {
+		if subtle.ConstantTimeCompare([]byte(token), k) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) lookup(name string) (CompletionFunc, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fn, ok := s.models[name]
+	return fn, ok
+}
+
+func (s *Server) handleModels(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "invalid_request_error", "", "Only GET is supported.")
+		return
+	}
+
+	s.mu.RLock()
+	names := make([]string, 0, len(s.models))
+	for name := range s.models {
+		names = append(names, name)
+	}
+	s.mu.RUnlock()
+	sort.Strings(names)
+
+	models := make([]openai.Model, 0, len(names))
+	for _, name := range names {
+		models = append(models, openai.Model{ID: name, Object: "model", CreatedAt: s.created, OwnedBy: "forza"})
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Object string         `json:"object"`
+		Data   []openai.Model `json:"data"`
+	}{"list", models})
+}
+
+func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "invalid_request_error", "", "Only POST is supported.")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, s.maxBodyBytes)
+	var req openai.ChatCompletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, "invalid_request_error", "", fmt.Sprintf("Request body exceeds %d bytes.", s.maxBodyBytes))
+			return
+		}
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "", "Invalid JSON body: "+err.Error())
+		return
+	}
+
+	fn, ok := s.lookup(req.Model)
+	if !ok {
+		writeError(w, http.StatusNotFound, "invalid_request_error", "model_not_found", fmt.Sprintf("The model %q does not exist.", req.Model))
+		return
+	}
+	if len(req.Messages) == 0 {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "", "messages must not be empty.")
+		return
+	}
+	prompt, history := splitConversation(req.Messages)
+	if prompt == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "", "The last message must have text content.")
+		return
+	}
+
+	tracker := forza.NewCostTracker()
+	ctx := forza.ContextWithCostTracker(r.Context(), tracker)
+	id := "chatcmpl-" + randomID()
+
+	if req.Stream {
+		s.stream(ctx, w, id, req.Model, func() (string, error) { return fn(ctx, prompt, history) })
+		return
+	}
+
+	answer, err := fn(ctx, prompt, history)
+	if err != nil {
+		s.logError(req.Model, err)
+		status, errType, message := errorStatus(err)
+		writeError(w, status, errType, "", message)
+		return
+	}
+
+	usage := tracker.Usage()
+	writeJSON(w, http.StatusOK, openai.ChatCompletionResponse{
+		ID:      id,
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   req.Model,
+		Choices: []openai.ChatCompletionChoice{{
+			Index:        0,
+			Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: answer},
+			FinishReason: openai.FinishReasonStop,
+		}},
+		Usage: openai.Usage{
+			PromptTokens:     usage.PromptTokens,
+			CompletionTokens: usage.CompletionTokens,
+			TotalTokens:      usage.TotalTokens(),
+		},
+	})
+}
+
+// stream answers with server-sent events. Agents return whole completions, so
+// the answer arrives as a single content chunk after the completion ends, not
+// as token deltas; the role chunk is sent immediately and comments keep the
+// connection alive while waiting.
+func (s *Server) stream(ctx context.Context, w http.ResponseWriter, id, model string, complete func() (string, error)) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "server_error", "", "Streaming is not supported by this connection.")
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	created := time.Now().Unix()
+	send := func(delta openai.ChatCompletionStreamChoiceDelta, finish openai.FinishReason) {
+		data, _ := json.Marshal(openai.ChatCompletionStreamResponse{
+			ID:      id,
+			Object:  "chat.completion.chunk",
+			Created: created,
+			Model:   model,
+			Choices: []openai.ChatCompletionStreamChoice{{Index: 0, Delta: delta, FinishReason: finish}},
+		})
+		fmt.Fprintf(w, "data: %s\n\n", data)
+		flusher.Flush()
+	}
+	send(openai.ChatCompletionStreamChoiceDelta{Role: openai.ChatMessageRoleAssistant}, "")
+
+	type result struct {
+		answer string
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		answer, err := complete()
+		done <- result{answer, err}
+	}()
+
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case <-ctx.Done():
+			return
+		case res := <-done:
+			if res.err != nil {
+				s.logError(model, res.err)
+				_, errType, message := errorStatus(res.err)
+				data, _ := json.Marshal(errorBody(errType, "", message))
+				fmt.Fprintf(w, "data: %s\n\n", data)
+			} else {
+				send(openai.ChatCompletionStreamChoiceDelta{Content: res.answer}, "")
+				send(openai.ChatCompletionStreamChoiceDelta{}, openai.FinishReasonStop)
+			}
+			fmt.Fprint(w, "data: [DONE]\n\n")
+			flusher.Flush()
+			return
+		}
+	}
+}
+
+func (s *Server) logError(model string, err error) {
+	if s.logger != nil {
+		s.logger.Error("completion failed", "model", model, "error", err)
+	}
+}
+
+// splitConversation returns the text of the last message and renders the
+// earlier messages as "role: content" lines.
+func splitConversation(messages []openai.ChatCompletionMessage) (prompt, history string) {
+	lines := make([]string, 0, len(messages)-1)
+	for _, m := range messages[:len(messages)-1] {
+		if text := messageText(m); text != "" {
+			lines = append(lines, m.Role+": "+text)
+		}
+	}
+	return messageText(messages[len(messages)-1]), strings.Join(lines, "\n")
+}
+
+// messageText returns a message's content, joining the text parts of
+// multi-part content.
+func messageText(m openai.ChatCompletionMessage) string {
+	if m.Content != "" || len(m.MultiContent) == 0 {
+		return m.Content
+	}
+	var parts []string
+	for _, p := range m.MultiContent {
+		if p.Type == openai.ChatMessagePartTypeText {
+			parts = append(parts, p.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// errorStatus maps a completion error to an HTTP status, an OpenAI error type
+// and a message for the client. The message never includes err's text.
+func errorStatus(err error) (int, string, string) {
+	switch {
+	case errors.Is(err, forza.ErrContextOverflow):
+		return http.StatusBadRequest, "invalid_request_error", "The conversation does not fit in the model's context window."
+	case errors.Is(err, forza.ErrTooManyArgs), errors.Is(err, forza.ErrMissingPrompt):
+		return http.StatusBadRequest, "invalid_request_error", "The request could not be turned into a prompt."
+	case errors.Is(err, forza.ErrBudgetExceeded):
+		return http.StatusTooManyRequests, "insufficient_quota", "The cost budget for this request was exceeded."
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, "server_error", "The completion timed out."
+	default:
+		return http.StatusBadGateway, "server_error", "The completion failed."
+	}
+}
+
+type apiError struct {
+	Message string  `json:"message"`
+	Type    string  `json:"type"`
+	Code    *string `json:"code"`
+}
+
+func errorBody(errType, code, message string) map[string]apiError {
+	e := apiError{Message: message, Type: errType}
+	if code != "" {
+		e.Code = &code
+	}
+	return map[string]apiError{"error": e}
+}
+
+func writeError(w http.ResponseWriter, status int, errType, code, message string) {
+	writeJSON(w, status, errorBody(errType, code, message))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func randomID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
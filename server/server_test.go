@@ -0,0 +1,238 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/vitoraguila/forza"
+)
+
+// newTestServer serves s and returns an OpenAI SDK client pointed at it.
+func newTestServer(t *testing.T, s *Server, apiKey string) *openai.Client {
+	t.Helper()
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	config := openai.DefaultConfig(apiKey)
+	config.BaseURL = ts.URL + "/v1"
+	return openai.NewClientWithConfig(config)
+}
+
+func echoCompletion(ctx context.Context, prompt, history string) (string, error) {
+	return "prompt=" + prompt + " history=" + history, nil
+}
+
+func TestServer_ChatCompletion(t *testing.T) {
+	s := New()
+	if err := s.Handle("echo", echoCompletion); err != nil {
+		t.Fatal(err)
+	}
+	client := newTestServer(t, s, "")
+
+	resp, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model: "echo",
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: "be brief"},
+			{Role: openai.ChatMessageRoleUser, Content: "hi"},
+			{Role: openai.ChatMessageRoleAssistant, Content: "hello"},
+			{Role: openai.ChatMessageRoleUser, Content: "how are you?"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "prompt=how are you? history=system: be brief\nuser: hi\nassistant: hello"
+	if len(resp.Choices) != 1 || resp.Choices[0].Message.Content != want {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if resp.Model != "echo" || resp.Choices[0].FinishReason != openai.FinishReasonStop || !strings.HasPrefix(resp.ID, "chatcmpl-") {
+		t.Errorf("unexpected metadata %+v", resp)
+	}
+}
+
+func TestServer_Stream(t *testing.T) {
+	s := New()
+	s.Handle("echo", echoCompletion)
+	client := newTestServer(t, s, "")
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model:    "echo",
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "hi"}},
+		Stream:   true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer stream.Close()
+
+	var content strings.Builder
+	var finish openai.FinishReason
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		content.WriteString(chunk.Choices[0].Delta.Content)
+		if chunk.Choices[0].FinishReason != "" {
+			finish = chunk.Choices[0].FinishReason
+		}
+	}
+	if content.String() != "prompt=hi history=" || finish != openai.FinishReasonStop {
+		t.Errorf("unexpected stream %q (finish %q)", content.String(), finish)
+	}
+}
+
+func TestServer_Models(t *testing.T) {
+	s := New()
+	s.Handle("writer", echoCompletion)
+	s.Handle("analyst", echoCompletion)
+	client := newTestServer(t, s, "")
+
+	list, err := client.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list.Models) != 2 || list.Models[0].ID != "analyst" || list.Models[1].ID != "writer" {
+		t.Errorf("unexpected models %+v", list.Models)
+	}
+
+	if err := s.Handle("writer", echoCompletion); !errors.Is(err, ErrDuplicateModel) {
+		t.Errorf("expected ErrDuplicateModel, got %v", err)
+	}
+	if err := s.Handle("", echoCompletion); !errors.Is(err, ErrEmptyModelName) {
+		t.Errorf("expected ErrEmptyModelName, got %v", err)
+	}
+}
+
+func TestServer_Auth(t *testing.T) {
+	s := New().WithAPIKeys("secret")
+	s.Handle("echo", echoCompletion)
+
+	if _, err := newTestServer(t, s, "wrong").ListModels(context.Background()); !isStatus(err, http.StatusUnauthorized) {
+		t.Errorf("expected 401, got %v", err)
+	}
+	if _, err := newTestServer(t, s, "secret").ListModels(context.Background()); err != nil {
+		t.Errorf("expected the right key to be accepted, got %v", err)
+	}
+}
+
+func TestServer_RequestErrors(t *testing.T) {
+	s := New().WithMaxBodyBytes(200)
+	s.Handle("echo", echoCompletion)
+	s.Handle("broken", func(context.Context, string, string) (string, error) {
+		return "", forza.ErrBudgetExceeded
+	})
+	client := newTestServer(t, s, "")
+	hi := []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "hi"}}
+
+	tests := []struct {
+		name   string
+		req    openai.ChatCompletionRequest
+		status int
+	}{
+		{"unknown model", openai.ChatCompletionRequest{Model: "nope", Messages: hi}, http.StatusNotFound},
+		{"no messages", openai.ChatCompletionRequest{Model: "echo"}, http.StatusBadRequest},
+		{"too large", openai.ChatCompletionRequest{Model: "echo", Messages: []openai.ChatCompletionMessage{{Role: "user", Content: strings.Repeat("x", 300)}}}, http.StatusRequestEntityTooLarge},
+		{"budget", openai.ChatCompletionRequest{Model: "broken", Messages: hi}, http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.CreateChatCompletion(context.Background(), tt.req)
+			if !isStatus(err, tt.status) {
+				t.Errorf("expected status %d, got %v", tt.status, err)
+			}
+		})
+	}
+}
+
+func TestServer_HidesUpstreamErrors(t *testing.T) {
+	s := New()
+	s.Handle("leaky", func(context.Context, string, string) (string, error) {
+		return "", errors.New("POST https://internal.example/v1: 500 secret upstream body")
+	})
+	client := newTestServer(t, s, "")
+	req := openai.ChatCompletionRequest{Model: "leaky", Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "hi"}}}
+
+	_, err := client.CreateChatCompletion(context.Background(), req)
+	if !isStatus(err, http.StatusBadGateway) || strings.Contains(err.Error(), "secret") || !strings.Contains(err.Error(), "The completion failed.") {
+		t.Errorf("expected a generic 502, got %v", err)
+	}
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer stream.Close()
+	for err == nil {
+		_, err = stream.Recv()
+	}
+	if strings.Contains(err.Error(), "secret") || strings.Contains(err.Error(), "internal.example") {
+		t.Errorf("expected the stream error to hide upstream details, got %v", err)
+	}
+}
+
+func TestAgentCompletion(t *testing.T) {
+	var prompts []string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openai.ChatCompletionRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		prompts = append(prompts, req.Messages[len(req.Messages)-1].Content)
+		json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: "from the agent"}}},
+			Usage:   openai.Usage{PromptTokens: 7, CompletionTokens: 3, TotalTokens: 10},
+		})
+	}))
+	defer backend.Close()
+
+	agent := forza.NewAgent().WithRole("r").WithBackstory("b").WithGoal("g")
+	config := forza.NewLLMConfig().
+		WithProvider(forza.ProviderOllama).
+		WithModel("llama3.1").
+		WithOllamaCredentials(backend.URL + "/v1")
+	fn, err := AgentCompletion(agent, config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := New()
+	s.Handle("assistant", fn)
+	client := newTestServer(t, s, "")
+
+	resp, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model: "assistant",
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleUser, Content: "first"},
+			{Role: openai.ChatMessageRoleUser, Content: "second"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Choices[0].Message.Content != "from the agent" || resp.Usage.TotalTokens != 10 {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if len(prompts) != 1 || !strings.HasPrefix(prompts[0], "second") || !strings.HasSuffix(prompts[0], "user: first") {
+		t.Errorf("expected the earlier messages as context, got %q", prompts)
+	}
+
+	if _, err := AgentCompletion(forza.NewAgent(), config); !errors.Is(err, forza.ErrMissingRole) {
+		t.Errorf("expected the agent to be validated up front, got %v", err)
+	}
+}
+
+func isStatus(err error, status int) bool {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode == status
+	}
+	var reqErr *openai.RequestError
+	return errors.As(err, &reqErr) && reqErr.HTTPStatusCode == status
+}
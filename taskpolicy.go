@@ -0,0 +1,134 @@
+package forza
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TaskOption configures a task wrapped by WrapTask.
+type TaskOption func(*taskPolicy)
+
+type taskPolicy struct {
+	timeout  time.Duration
+	retries  int
+	backoff  time.Duration
+	retryOn  []error
+	fallback *string
+	optional bool
+}
+
+// TaskTimeout limits each attempt of the task to d by giving it a context
+// with that deadline. A task that runs out of time fails with ErrTaskTimeout.
+func TaskTimeout(d time.Duration) TaskOption {
+	return func(p *taskPolicy) {
+		p.timeout = d
+	}
+}
+
+// TaskRetries retries a failed task up to n more times, waiting backoff
+// before the first retry and doubling it after each one. If errs are given,
+// only errors matching one of them (with errors.Is) are retried; otherwise
+// every error is.
+func TaskRetries(n int, backoff time.Duration, errs ...error) TaskOption {
+	return func(p *taskPolicy) {
+		p.retries = n
+		p.backoff = backoff
+		p.retryOn = errs
+	}
+}
+
+// TaskFallback makes a failed task return value instead of its error.
+func TaskFallback(value string) TaskOption {
+	return func(p *taskPolicy) {
+		p.fallback = &value
+	}
+}
+
+// TaskOptional makes a failed task succeed without changing the data flow:
+// it returns its context argument unchanged, so an optional step in a chain
+// passes the previous result through, and an empty string when called
+// without context.
+func TaskOptional() TaskOption {
+	return func(p *taskPolicy) {
+		p.optional = true
+	}
+}
+
+// WrapTask applies per-task limits to a task so it can be used with AddTasks,
+// CreateChain or a DAG like any other TaskChainFn:
+//
+//	research := forza.WrapTask(researcher.Completion,
+//		forza.TaskTimeout(30*time.Second),
+//		forza.TaskRetries(2, time.Second, forza.ErrTaskTimeout),
+//		forza.TaskOptional(),
+//	)
+//
+// Retries run before the fallback or optional handling applies. A task whose
+// caller's context is done is never retried and always returns the error, so
+// cancelling a run still stops it.
+func WrapTask(task TaskChainFn, opts ...TaskOption) TaskChainFn {
+	var p taskPolicy
+	for _, opt := range opts {
+		opt(&p)
+	}
+
+	return func(ctx context.Context, params ...string) (string, error) {
+		if task == nil {
+			return "", ErrNilTask
+		}
+
+		result, err := p.attempt(ctx, task, params)
+		backoff := p.backoff
+		for retry := 0; err != nil && retry < p.retries && ctx.Err() == nil && p.shouldRetry(err); retry++ {
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			result, err = p.attempt(ctx, task, params)
+		}
+
+		if err == nil || ctx.Err() != nil {
+			return result, err
+		}
+		switch {
+		case p.fallback != nil:
+			return *p.fallback, nil
+		case p.optional:
+			if len(params) > 0 {
+				return params[0], nil
+			}
+			return "", nil
+		}
+		return result, err
+	}
+}
+
+// attempt runs the task once, under the policy's timeout if one is set.
+func (p *taskPolicy) attempt(ctx context.Context, task TaskChainFn, params []string) (string, error) {
+	if p.timeout <= 0 {
+		return task(ctx, params...)
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	result, err := task(attemptCtx, params...)
+	if err != nil && ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+		return result, fmt.Errorf("%w after %s: %w", ErrTaskTimeout, p.timeout, err)
+	}
+	return result, err
+}
+
+func (p *taskPolicy) shouldRetry(err error) bool {
+	if len(p.retryOn) == 0 {
+		return true
+	}
+	for _, target := range p.retryOn {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package forza
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// flakyTask fails with err for the first failures calls and then succeeds.
+func flakyTask(failures int, err error) (TaskChainFn, *int) {
+	calls := 0
+	return func(ctx context.Context, params ...string) (string, error) {
+		calls++
+		if calls <= failures {
+			return "", err
+		}
+		return "ok", nil
+	}, &calls
+}
+
+func TestWrapTask_Timeout(t *testing.T) {
+	slow := func(ctx context.Context, params ...string) (string, error) {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(5 * time.Second):
+			return "late", nil
+		}
+	}
+
+	start := time.Now()
+	_, err := WrapTask(slow, TaskTimeout(20*time.Millisecond))(context.Background())
+	if !errors.Is(err, ErrTaskTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected ErrTaskTimeout, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("expected the timeout to stop the task")
+	}
+}
+
+func TestWrapTask_Retries(t *testing.T) {
+	task, calls := flakyTask(2, ErrCompletionFailed)
+	result, err := WrapTask(task, TaskRetries(2, time.Millisecond))(context.Background())
+	if err != nil || result != "ok" || *calls != 3 {
+		t.Errorf("expected success on the third call, got %q, %v after %d calls", result, err, *calls)
+	}
+
+	task, calls = flakyTask(5, ErrCompletionFailed)
+	if _, err := WrapTask(task, TaskRetries(2, time.Millisecond))(context.Background()); !errors.Is(err, ErrCompletionFailed) || *calls != 3 {
+		t.Errorf("expected the last error after 3 calls, got %v after %d", err, *calls)
+	}
+
+	task, calls = flakyTask(1, ErrMissingAPIKey)
+	if _, err := WrapTask(task, TaskRetries(3, time.Millisecond, ErrCompletionFailed))(context.Background()); !errors.Is(err, ErrMissingAPIKey) || *calls != 1 {
+		t.Errorf("expected unlisted errors not to be retried, got %v after %d calls", err, *calls)
+	}
+}
+
+func TestWrapTask_FallbackAndOptional(t *testing.T) {
+	failing := mockErrorTask("boom")
+
+	if result, err := WrapTask(failing, TaskFallback("n/a"))(context.Background()); err != nil || result != "n/a" {
+		t.Errorf("expected the fallback value, got %q, %v", result, err)
+	}
+
+	p := NewPipeline()
+	chain := p.CreateChain(mockTask("draft"), WrapTask(failing, TaskOptional()), mockTask("final"))
+	result, err := chain(context.Background())
+	if err != nil || result != "final [ctx:draft]" {
+		t.Errorf("expected the optional step to pass its input through, got %q, %v", result, err)
+	}
+
+	p.AddTasks(mockTask("a"), WrapTask(failing, TaskOptional()))
+	results, err := p.RunConcurrently(context.Background())
+	if err != nil || results[0] != "a" || results[1] != "" {
+		t.Errorf("expected the optional failure not to fail the run, got %q, %v", results, err)
+	}
+}
+
+func TestWrapTask_CancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	task, calls := flakyTask(1, context.Canceled)
+
+	_, err := WrapTask(task, TaskRetries(3, time.Millisecond), TaskFallback("n/a"))(ctx)
+	if !errors.Is(err, context.Canceled) || *calls != 1 {
+		t.Errorf("expected cancellation to bypass retries and fallback, got %v after %d calls", err, *calls)
+	}
+}
@@ -0,0 +1,205 @@
+// Package tokenizer counts tokens offline, without calling a provider API.
+//
+// OpenAI models use the exact BPE encodings (cl100k_base, o200k_base), loaded
+// from data embedded in the binary. Claude, Gemini and Llama-family models
+// don't ship offline tokenizers, so they use character-based estimators tuned
+// to each family's typical characters-per-token ratio. Estimates round up.
+package tokenizer
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/pkoukk/tiktoken-go"
+	tiktoken_loader "github.com/pkoukk/tiktoken-go-loader"
+)
+
+// Encoding and estimator names.
+const (
+	CL100KBase      = "cl100k_base"
+	O200KBase       = "o200k_base"
+	ClaudeEstimate  = "claude-estimate"
+	GeminiEstimate  = "gemini-estimate"
+	LlamaEstimate   = "llama-estimate"
+	GenericEstimate = "generic-estimate"
+)
+
+// Tokenizer counts and trims text in model tokens.
+type Tokenizer interface {
+	// Name returns the encoding or estimator name.
+	Name() string
+	// Count returns the number of tokens in text.
+	Count(text string) int
+	// Head returns the longest prefix of text that fits in maxTokens.
+	Head(text string, maxTokens int) string
+	// Tail returns the longest suffix of text that fits in maxTokens.
+	Tail(text string, maxTokens int) string
+}
+
+func init() {
+	tiktoken.SetBpeLoader(tiktoken_loader.NewOfflineLoader())
+}
+
+// openAIEncodings maps model name prefixes to BPE encodings. Longer prefixes
+// must come first.
+var openAIEncodings = []struct {
+	prefix   string
+	encoding string
+}{
+	{"gpt-4o", O200KBase},
+	{"gpt-4.1", O200KBase},
+	{"gpt-5", O200KBase},
+	{"o1", O200KBase},
+	{"o3", O200KBase},
+	{"o4", O200KBase},
+	{"codex", O200KBase},
+	{"gpt-4", CL100KBase},
+	{"gpt-3.5", CL100KBase},
+	{"text-embedding", CL100KBase},
+}
+
+// ForModel returns the tokenizer for a model name. Unknown models get a
+// generic estimator.
+func ForModel(model string) Tokenizer {
+	m := strings.ToLower(model)
+	for _, e := range openAIEncodings {
+		if strings.HasPrefix(m, e.prefix) {
+			if t, err := ForEncoding(e.encoding); err == nil {
+				return t
+			}
+		}
+	}
+	switch {
+	case strings.HasPrefix(m, "claude"):
+		return claude
+	case strings.HasPrefix(m, "gemini"), strings.HasPrefix(m, "gemma"):
+		return gemini
+	case strings.HasPrefix(m, "llama"), strings.HasPrefix(m, "mistral"),
+		strings.HasPrefix(m, "mixtral"), strings.HasPrefix(m, "phi"):
+		return llama
+	}
+	return generic
+}
+
+var (
+	bpeMu    sync.Mutex
+	bpeCache = make(map[string]*bpeTokenizer)
+)
+
+// ForEncoding returns an exact BPE tokenizer for one of the embedded OpenAI
+// encodings (CL100KBase or O200KBase). Encodings are loaded on first use.
+func ForEncoding(name string) (Tokenizer, error) {
+	bpeMu.Lock()
+	defer bpeMu.Unlock()
+	if t, ok := bpeCache[name]; ok {
+		return t, nil
+	}
+	enc, err := tiktoken.GetEncoding(name)
+	if err != nil {
+		return nil, err
+	}
+	t := &bpeTokenizer{name: name, enc: enc}
+	bpeCache[name] = t
+	return t, nil
+}
+
+// bpeTokenizer is an exact tokenizer backed by a tiktoken encoding.
+type bpeTokenizer struct {
+	name string
+	enc  *tiktoken.Tiktoken
+}
+
+func (b *bpeTokenizer) Name() string { return b.name }
+
+func (b *bpeTokenizer) Count(text string) int {
+	return len(b.enc.EncodeOrdinary(text))
+}
+
+func (b *bpeTokenizer) Head(text string, maxTokens int) string {
+	if maxTokens <= 0 {
+		return ""
+	}
+	ids := b.enc.EncodeOrdinary(text)
+	if len(ids) <= maxTokens {
+		return text
+	}
+	return validUTF8Prefix(b.enc.Decode(ids[:maxTokens]))
+}
+
+func (b *bpeTokenizer) Tail(text string, maxTokens int) string {
+	if maxTokens <= 0 {
+		return ""
+	}
+	ids := b.enc.EncodeOrdinary(text)
+	if len(ids) <= maxTokens {
+		return text
+	}
+	return validUTF8Suffix(b.enc.Decode(ids[len(ids)-maxTokens:]))
+}
+
+// Calibrated estimators.
+var (
+	claude  = &estimator{name: ClaudeEstimate, charsPerToken: 3.5}
+	gemini  = &estimator{name: GeminiEstimate, charsPerToken: 4.0}
+	llama   = &estimator{name: LlamaEstimate, charsPerToken: 3.7}
+	generic = &estimator{name: GenericEstimate, charsPerToken: 4.0}
+)
+
+// estimator approximates a tokenizer from the text's character count. It
+// rounds up so that estimates err on the side of more tokens.
+type estimator struct {
+	name          string
+	charsPerToken float64
+}
+
+func (e *estimator) Name() string { return e.name }
+
+func (e *estimator) Count(text string) int {
+	n := utf8.RuneCountInString(text)
+	if n == 0 {
+		return 0
+	}
+	return int(math.Ceil(float64(n) / e.charsPerToken))
+}
+
+func (e *estimator) Head(text string, maxTokens int) string {
+	if e.Count(text) <= maxTokens {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:e.maxRunes(maxTokens)])
+}
+
+func (e *estimator) Tail(text string, maxTokens int) string {
+	if e.Count(text) <= maxTokens {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[len(runes)-e.maxRunes(maxTokens):])
+}
+
+func (e *estimator) maxRunes(maxTokens int) int {
+	if maxTokens <= 0 {
+		return 0
+	}
+	return int(float64(maxTokens) * e.charsPerToken)
+}
+
+// validUTF8Prefix drops a trailing partial rune left by cutting between
+// tokens that split a multi-byte character.
+func validUTF8Prefix(s string) string {
+	for len(s) > 0 && !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// validUTF8Suffix drops a leading partial rune.
+func validUTF8Suffix(s string) string {
+	for len(s) > 0 && !utf8.ValidString(s) {
+		s = s[1:]
+	}
+	return s
+}
@@ -0,0 +1,112 @@
+package tokenizer
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestForModel(t *testing.T) {
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{"gpt-4o-mini", O200KBase},
+		{"gpt-4o", O200KBase},
+		{"o1-mini", O200KBase},
+		{"gpt-5", O200KBase},
+		{"codex-5.2", O200KBase},
+		{"gpt-4", CL100KBase},
+		{"gpt-4-turbo", CL100KBase},
+		{"gpt-3.5-turbo", CL100KBase},
+		{"claude-sonnet-4-20250514", ClaudeEstimate},
+		{"gemini-2.5-pro", GeminiEstimate},
+		{"llama3.1", LlamaEstimate},
+		{"mistral", LlamaEstimate},
+		{"some-custom-model", GenericEstimate},
+	}
+	for _, tt := range tests {
+		if got := ForModel(tt.model).Name(); got != tt.want {
+			t.Errorf("ForModel(%q) = %q, want %q", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestForEncoding_Unknown(t *testing.T) {
+	if _, err := ForEncoding("nope"); err == nil {
+		t.Error("expected error for unknown encoding")
+	}
+}
+
+func TestBPE_Count(t *testing.T) {
+	tests := []struct {
+		encoding string
+		text     string
+		want     int
+	}{
+		{CL100KBase, "hello world", 2},
+		{O200KBase, "hello world", 2},
+		{CL100KBase, "", 0},
+	}
+	for _, tt := range tests {
+		tok, err := ForEncoding(tt.encoding)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := tok.Count(tt.text); got != tt.want {
+			t.Errorf("%s: Count(%q) = %d, want %d", tt.encoding, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestBPE_HeadAndTail(t *testing.T) {
+	tok, _ := ForEncoding(CL100KBase)
+	text := "one two three four five six"
+
+	if got := tok.Head(text, 2); got != "one two" {
+		t.Errorf("Head = %q, want %q", got, "one two")
+	}
+	if got := tok.Tail(text, 2); got != " five six" {
+		t.Errorf("Tail = %q, want %q", got, " five six")
+	}
+	if got := tok.Head(text, 100); got != text {
+		t.Errorf("expected text unchanged when it fits, got %q", got)
+	}
+	if got := tok.Tail(text, 0); got != "" {
+		t.Errorf("expected empty tail for zero tokens, got %q", got)
+	}
+}
+
+func TestBPE_HeadKeepsValidUTF8(t *testing.T) {
+	tok, _ := ForEncoding(CL100KBase)
+	text := strings.Repeat("日本語のテキスト", 20)
+	for n := 1; n < 20; n++ {
+		if got := tok.Head(text, n); !utf8.ValidString(got) {
+			t.Fatalf("Head(%d) returned invalid UTF-8", n)
+		}
+		if got := tok.Tail(text, n); !utf8.ValidString(got) {
+			t.Fatalf("Tail(%d) returned invalid UTF-8", n)
+		}
+	}
+}
+
+func TestEstimator(t *testing.T) {
+	tok := ForModel("claude-opus-4-20250514")
+	text := strings.Repeat("a", 35)
+
+	if got := tok.Count(text); got != 10 {
+		t.Errorf("Count = %d, want 10", got)
+	}
+	if got := tok.Count(""); got != 0 {
+		t.Errorf("Count(\"\") = %d, want 0", got)
+	}
+	if got := tok.Head("abcdefghij", 2); got != "abcdefg" {
+		t.Errorf("Head = %q, want %q", got, "abcdefg")
+	}
+	if got := tok.Tail("abcdefghij", 2); got != "defghij" {
+		t.Errorf("Tail = %q, want %q", got, "defghij")
+	}
+	if got := tok.Count(tok.Head(strings.Repeat("word ", 200), 50)); got > 50 {
+		t.Errorf("Head result should fit in 50 tokens, got %d", got)
+	}
+}
@@ -0,0 +1,170 @@
+package forza
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Task is a typed pipeline step. Unlike TaskChainFn, its input and output
+// can be any Go type, so a structured extraction step can hand a struct
+// directly to the next step. Compose tasks with Then, Parallel and Map, and
+// convert from and to string tasks with FromChainFn, FromAgent and ToChainFn.
+type Task[In, Out any] func(ctx context.Context, in In) (Out, error)
+
+// Then returns a task that runs first and passes its output to second.
+func Then[A, B, C any](first Task[A, B], second Task[B, C]) Task[A, C] {
+	return func(ctx context.Context, in A) (C, error) {
+		var zero C
+		if first == nil || second == nil {
+			return zero, ErrNilTask
+		}
+		mid, err := first(ctx, in)
+		if err != nil {
+			return zero, err
+		}
+		return second(ctx, mid)
+	}
+}
+
+// Parallel returns a task that runs every task concurrently on the same input
+// and returns their outputs in order. Failures are reported like
+// Pipeline.RunConcurrently, as a *PipelineError.
+func Parallel[In, Out any](tasks ...Task[In, Out]) Task[In, []Out] {
+	return func(ctx context.Context, in In) ([]Out, error) {
+		return runTyped(ctx, len(tasks), func(ctx context.Context, i int) (Out, error) {
+			if tasks[i] == nil {
+				var zero Out
+				return zero, fmt.Errorf("%w: task at index %d", ErrNilTask, i)
+			}
+			return tasks[i](ctx, in)
+		})
+	}
+}
+
+// Map returns a task that runs task concurrently on every element of its
+// input and returns the outputs in order. Failures are reported as a
+// *PipelineError whose task indexes are element indexes.
+func Map[In, Out any](task Task[In, Out]) Task[[]In, []Out] {
+	return func(ctx context.Context, in []In) ([]Out, error) {
+		if task == nil {
+			return nil, ErrNilTask
+		}
+		return runTyped(ctx, len(in), func(ctx context.Context, i int) (Out, error) {
+			return task(ctx, in[i])
+		})
+	}
+}
+
+// runTyped calls fn for 0..n-1 concurrently, giving each call a "task N"
+// cost tracker child and turning panics into errors.
+func runTyped[Out any](ctx context.Context, n int, fn func(context.Context, int) (Out, error)) ([]Out, error) {
+	outputs := make([]Out, n)
+	taskErrs := make([]*TaskError, n)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			start := time.Now()
+			defer func() {
+				if r := recover(); r != nil {
+					taskErrs[index] = &TaskError{Index: index, Duration: time.Since(start), Err: fmt.Errorf("panicked: %v", r)}
+				}
+			}()
+			out, err := fn(taskContext(ctx, index), index)
+			outputs[index] = out
+			if err != nil {
+				taskErrs[index] = &TaskError{Index: index, Duration: time.Since(start), Err: err}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var errs []*TaskError
+	for _, err := range taskErrs {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return outputs, &PipelineError{Errors: errs}
+	}
+	return outputs, nil
+}
+
+// FromChainFn adapts a TaskChainFn to a typed task. A non-empty input is
+// passed as the function's context argument; an empty one calls it without
+// context, like the first step of a chain.
+func FromChainFn(fn TaskChainFn) Task[string, string] {
+	return func(ctx context.Context, in string) (string, error) {
+		if fn == nil {
+			return "", ErrNilTask
+		}
+		if in == "" {
+			return fn(ctx)
+		}
+		return fn(ctx, in)
+	}
+}
+
+// FromAgent adapts an LLMAgent's Completion to a typed task whose input is
+// the completion's context.
+func FromAgent(agent LLMAgent) Task[string, string] {
+	return FromChainFn(agent.Completion)
+}
+
+// ToChainFn adapts a typed string task to a TaskChainFn for use with
+// Pipeline and DAG. The task receives the context argument, or an empty
+// string when there is none.
+func ToChainFn(task Task[string, string]) TaskChainFn {
+	return func(ctx context.Context, params ...string) (string, error) {
+		if task == nil {
+			return "", ErrNilTask
+		}
+		if len(params) > 1 {
+			return "", ErrTooManyArgs
+		}
+		var in string
+		if len(params) == 1 {
+			in = params[0]
+		}
+		return task(ctx, in)
+	}
+}
+
+// JSONTask returns a task that runs the agent's completion and decodes the
+// answer as JSON into Out. The agent's prompt should ask for JSON matching
+// Out; a surrounding Markdown code fence is ignored.
+func JSONTask[Out any](agent LLMAgent) Task[string, Out] {
+	complete := FromAgent(agent)
+	return func(ctx context.Context, in string) (Out, error) {
+		var out Out
+		answer, err := complete(ctx, in)
+		if err != nil {
+			return out, err
+		}
+		if err := json.Unmarshal([]byte(stripCodeFence(answer)), &out); err != nil {
+			return out, fmt.Errorf("%w: %v", ErrInvalidOutput, err)
+		}
+		return out, nil
+	}
+}
+
+// stripCodeFence removes a Markdown code fence, with or without a language
+// tag, around s.
+func stripCodeFence(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "```") || !strings.HasSuffix(s, "```") || len(s) < 6 {
+		return s
+	}
+	s = strings.TrimSuffix(s[3:], "```")
+	if nl := strings.IndexByte(s, '\n'); nl >= 0 {
+		s = s[nl+1:]
+	}
+	return strings.TrimSpace(s)
+}
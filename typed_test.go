@@ -0,0 +1,90 @@
+package forza
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type invoice struct {
+	Customer string  `json:"customer"`
+	Total    float64 `json:"total"`
+}
+
+func TestThen_PassesStructs(t *testing.T) {
+	extractor := &stubAgent{complete: func(ctx context.Context, params ...string) (string, error) {
+		return "```json\n{\"customer\": \"ACME\", \"total\": 12.5}\n```", nil
+	}}
+	summarize := Task[invoice, string](func(ctx context.Context, in invoice) (string, error) {
+		return in.Customer + " owes " + strconv.FormatFloat(in.Total, 'f', 2, 64), nil
+	})
+
+	result, err := Then(JSONTask[invoice](extractor), summarize)(context.Background(), "invoice text")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "ACME owes 12.50" {
+		t.Errorf("unexpected result %q", result)
+	}
+}
+
+func TestJSONTask_InvalidOutput(t *testing.T) {
+	agent := &stubAgent{complete: func(ctx context.Context, params ...string) (string, error) {
+		return "not json", nil
+	}}
+	if _, err := JSONTask[invoice](agent)(context.Background(), ""); !errors.Is(err, ErrInvalidOutput) {
+		t.Errorf("expected ErrInvalidOutput, got %v", err)
+	}
+}
+
+func TestParallelAndMap(t *testing.T) {
+	upper := Task[string, string](func(ctx context.Context, in string) (string, error) {
+		return strings.ToUpper(in), nil
+	})
+	length := Task[string, string](func(ctx context.Context, in string) (string, error) {
+		return strconv.Itoa(len(in)), nil
+	})
+
+	out, err := Parallel(upper, length)(context.Background(), "go")
+	if err != nil || strings.Join(out, ",") != "GO,2" {
+		t.Errorf("unexpected parallel output %v, %v", out, err)
+	}
+
+	out, err = Map(upper)(context.Background(), []string{"a", "b", "c"})
+	if err != nil || strings.Join(out, ",") != "A,B,C" {
+		t.Errorf("unexpected map output %v, %v", out, err)
+	}
+
+	failing := Task[string, string](func(ctx context.Context, in string) (string, error) {
+		if in == "b" {
+			panic("bad item")
+		}
+		return in, nil
+	})
+	out, err = Map(failing)(context.Background(), []string{"a", "b"})
+	var pipelineErr *PipelineError
+	if !errors.As(err, &pipelineErr) || pipelineErr.Errors[0].Index != 1 || out[0] != "a" {
+		t.Errorf("expected the failed element in a PipelineError, got %v (%v)", err, out)
+	}
+}
+
+func TestChainFnAdapters(t *testing.T) {
+	task := FromChainFn(mockTask("step"))
+	if out, _ := task(context.Background(), ""); out != "step" {
+		t.Errorf("expected no context for empty input, got %q", out)
+	}
+	if out, _ := task(context.Background(), "prev"); out != "step [ctx:prev]" {
+		t.Errorf("expected the input as context, got %q", out)
+	}
+
+	chain := NewPipeline().CreateChain(mockTask("first"), ToChainFn(Then(task, task)))
+	result, err := chain(context.Background())
+	if err != nil || result != "step [ctx:step [ctx:first]]" {
+		t.Errorf("unexpected chain result %q, %v", result, err)
+	}
+	if _, err := ToChainFn(task)(context.Background(), "a", "b"); !errors.Is(err, ErrTooManyArgs) {
+		t.Errorf("expected ErrTooManyArgs, got %v", err)
+	}
+}